@@ -1,22 +1,44 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/tuannvm/mcp-trino/internal/auth"
 	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/cors"
+	"github.com/tuannvm/mcp-trino/internal/format"
 	"github.com/tuannvm/mcp-trino/internal/handlers"
 	"github.com/tuannvm/mcp-trino/internal/trino"
 )
 
+// Transports selected by MCP_TRANSPORT
+const (
+	transportStdio      = "stdio"
+	transportSSE        = "http"
+	transportStreamable = "streamable-http"
+)
+
+// streamablePath is the endpoint of the streamable HTTP transport
+const streamablePath = "/mcp"
+
+// toolCallWait bounds how long shutdown waits for tool calls to return once their
+// queries have finished or been cancelled
+const toolCallWait = 5 * time.Second
+
 // These variables will be set during the build via ldflags
 var (
 	// Version is the server version, set by the build process
@@ -28,92 +50,102 @@ func main() {
 
 	// Initialize Trino configuration
 	log.Println("Loading Trino configuration...")
-	trinoConfig := config.NewTrinoConfig()
+	clustersConfig, err := config.NewClustersConfig()
+	if err != nil {
+		log.Fatalf("Failed to load Trino cluster configuration: %v", err)
+	}
 
-	// Initialize Trino client
-	log.Println("Connecting to Trino server...")
-	trinoClient, err := trino.NewClient(trinoConfig)
+	// Initialize Trino clients
+	log.Println("Connecting to Trino clusters...")
+	clusters, err := trino.NewClusters(clustersConfig)
 	if err != nil {
-		log.Fatalf("Failed to initialize Trino client: %v", err)
+		log.Fatalf("Failed to initialize Trino clients: %v", err)
 	}
-	defer func() {
-		if err := trinoClient.Close(); err != nil {
-			log.Printf("Error closing Trino client: %v", err)
-		}
-	}()
 
-	// Test connection by listing catalogs
-	log.Println("Testing Trino connection...")
-	catalogs, err := trinoClient.ListCatalogs()
+	// Test the default cluster by listing catalogs; other clusters may be down
+	// without affecting it and report their health through list_clusters
+	log.Printf("Testing connection to Trino cluster %s...", clusters.DefaultName())
+	catalogs, err := clusters.Default().ListCatalogs(context.Background())
 	if err != nil {
 		log.Fatalf("Failed to connect to Trino: %v", err)
 	}
 	log.Printf("Connected to Trino server. Available catalogs: %s", strings.Join(catalogs, ", "))
+	if names := clusters.Names(); len(names) > 1 {
+		log.Printf("Configured Trino clusters: %s (default %s)", strings.Join(names, ", "), clusters.DefaultName())
+	}
+
+	// Default output format for query results
+	resultFormat := strings.ToLower(getEnv("MCP_RESULT_FORMAT", format.JSON))
+	if _, err := format.Get(resultFormat); err != nil {
+		log.Fatalf("Invalid MCP_RESULT_FORMAT: %v", err)
+	}
 
 	// Create and initialize MCP server
 	log.Println("Initializing MCP server...")
-	mcpServer := server.NewMCPServer("Trino MCP Server", Version)
-
-	// Initialize tool handlers
-	trinoHandlers := handlers.NewTrinoHandlers(trinoClient)
-	registerTrinoTools(mcpServer, trinoHandlers)
+	mcpServer, sessions, calls := newMCPServer(clusters, resultFormat)
 
 	// Choose server mode
-	transport := getEnv("MCP_TRANSPORT", "stdio")
+	transport := getEnv("MCP_TRANSPORT", transportStdio)
 
-	// Graceful shutdown
-	done := make(chan bool, 1)
-	go handleSignals(done)
+	// Graceful shutdown: on SIGINT or SIGTERM, refuse new tool calls and let running
+	// queries finish within the grace period
+	signals, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+	gracePeriod := config.ShutdownGracePeriod()
+	var shutdownOnce sync.Once
+	drain := func() {
+		shutdownOnce.Do(func() { shutdown(calls, clusters, gracePeriod) })
+	}
 
 	log.Printf("Starting MCP server with %s transport...", transport)
 	switch transport {
-	case "stdio":
-		if err := server.ServeStdio(mcpServer); err != nil {
+	case transportStdio:
+		// Keep serving while draining so running tool calls can still respond
+		listenCtx, stopListening := context.WithCancel(context.Background())
+		go func() {
+			<-signals.Done()
+			drain()
+			stopListening()
+		}()
+		err := server.NewStdioServer(mcpServer).Listen(listenCtx, os.Stdin, os.Stdout)
+		if err != nil && !errors.Is(err, context.Canceled) {
 			log.Fatalf("STDIO server error: %v", err)
 		}
-	case "http":
+		// The client may also end the session by closing stdin
+		drain()
+	case transportSSE, transportStreamable:
 		port := getEnv("MCP_PORT", "9097")
 		host := getEnv("MCP_HOST", "localhost")
 		addr := fmt.Sprintf(":%s", port)
+		opts := httpOptions{
+			transport:    transport,
+			baseURL:      fmt.Sprintf("http://%s:%s", host, port),
+			resultFormat: resultFormat,
+		}
 
-		// Create SSE server
-		log.Println("Setting up SSE server...")
-		baseURL := fmt.Sprintf("http://%s:%s", host, port)
-		sseServer := server.NewSSEServer(
-			mcpServer,
-			server.WithSSEEndpoint("/sse"),
-			server.WithMessageEndpoint("/api/v1"),
-			server.WithKeepAlive(true),
-			server.WithBaseURL(baseURL),
-			server.WithUseFullURLForMessageEndpoint(true),
-		)
-		log.Printf("SSE path: %s", sseServer.CompleteSsePath())
-		log.Printf("Message path: %s", sseServer.CompleteMessagePath())
+		// Authenticate callers and run their queries as their own Trino user
+		authConfig := config.NewAuthConfig()
+		if authConfig.Enabled() {
+			var err error
+			if opts.authenticator, opts.mapping, err = newAuthenticator(authConfig); err != nil {
+				log.Fatalf("Failed to initialize authentication: %v", err)
+			}
+		} else {
+			log.Println("WARNING: HTTP transport is unauthenticated; all queries run as TRINO_USER. Set MCP_AUTH_API_KEYS_FILE, MCP_AUTH_HMAC_SECRET_FILE or MCP_AUTH_JWKS_FILE to authenticate callers.")
+		}
 
+		// Only allow-listed origins may call the server from a browser
+		opts.origins = cors.ParseOrigins(getEnv("MCP_CORS_ALLOWED_ORIGINS", ""))
+		if len(opts.origins) > 0 {
+			log.Printf("CORS allowed origins: %s", strings.Join(opts.origins, ", "))
+		}
+
+		handler := newHTTPHandler(mcpServer, sessions, clusters, opts)
 		httpServer := &http.Server{
 			Addr: addr,
 			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				log.Printf("HTTP %s %s", r.Method, r.URL.Path)
-				// CORS
-				w.Header().Set("Access-Control-Allow-Origin", "*")
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept")
-
-				if r.Method == http.MethodOptions {
-					w.WriteHeader(http.StatusOK)
-					return
-				}
-				switch {
-				case r.URL.Path == "/sse":
-					w.Header().Set("Content-Type", "text/event-stream")
-					sseServer.ServeHTTP(w, r)
-				case r.Method == http.MethodPost && r.URL.Path == "/api/query":
-					handleTrinoQuery(w, r, trinoClient)
-				case r.Method == http.MethodGet && r.URL.Path == "/":
-					handleStatus(w, r)
-				default:
-					sseServer.ServeHTTP(w, r)
-				}
+				handler.ServeHTTP(w, r)
 			}),
 		}
 
@@ -123,62 +155,372 @@ func main() {
 			}
 		}()
 
-		<-done
+		<-signals.Done()
 		log.Println("Shutting down HTTP server...")
+		// Stop accepting connections; open streams stay up so running tool calls can respond
+		go func() { _ = httpServer.Shutdown(context.Background()) }()
+		drain()
 		_ = httpServer.Close()
 	default:
-		log.Fatalf("Unsupported transport: %s", transport)
+		log.Fatalf("Unsupported transport: %s (use %s, %s or %s)", transport, transportStdio, transportSSE, transportStreamable)
 	}
 
 	log.Println("Server shutdown complete")
 }
 
-func handleTrinoQuery(w http.ResponseWriter, r *http.Request, client *trino.Client) {
-	if client == nil {
+// newMCPServer creates the MCP server with the Trino tools and resources registered
+func newMCPServer(clusters *trino.Clusters, resultFormat string) (*server.MCPServer, *handlers.SessionContexts, *handlers.ToolCalls) {
+	// Bind tool calls to their session so a client disconnect cancels its queries
+	sessions := handlers.NewSessionContexts()
+	hooks := &server.Hooks{}
+	sessions.Register(hooks)
+	// Track tool calls so shutdown can wait for them
+	calls := &handlers.ToolCalls{}
+	mcpServer := server.NewMCPServer("Trino MCP Server", Version,
+		server.WithHooks(hooks),
+		server.WithToolHandlerMiddleware(calls.Middleware),
+		server.WithToolHandlerMiddleware(sessions.ToolMiddleware),
+		server.WithResourceCapabilities(false, false),
+	)
+
+	trinoHandlers := handlers.NewTrinoHandlers(clusters)
+	trinoHandlers.DefaultFormat = resultFormat
+	trinoHandlers.ProgressInterval = config.ProgressInterval()
+	registerTrinoTools(mcpServer, trinoHandlers)
+	registerTrinoResources(mcpServer, trinoHandlers)
+	return mcpServer, sessions, calls
+}
+
+// httpOptions configure the HTTP transports
+type httpOptions struct {
+	transport     string // transportSSE or transportStreamable
+	baseURL       string // URL clients reach the server at, for SSE message endpoints
+	resultFormat  string // Default format of /api/query results
+	authenticator auth.Authenticator
+	mapping       *auth.UserMapping
+	origins       []string
+}
+
+// newHTTPHandler serves the MCP endpoints of the transport next to the status page and
+// /api/query. Authentication and the CORS policy apply to all of them alike.
+func newHTTPHandler(mcpServer *server.MCPServer, sessions *handlers.SessionContexts, clusters *trino.Clusters, opts httpOptions) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/query", func(w http.ResponseWriter, r *http.Request) {
+		handleTrinoQuery(w, r, clusters, opts.resultFormat)
+	})
+	mux.HandleFunc("GET /{$}", handleStatus)
+
+	switch opts.transport {
+	case transportStreamable:
+		log.Println("Setting up streamable HTTP server...")
+		mux.Handle(streamablePath, server.NewStreamableHTTPServer(mcpServer, server.WithEndpointPath(streamablePath)))
+		log.Printf("MCP endpoint: %s", streamablePath)
+	default:
+		log.Println("Setting up SSE server...")
+		sseServer := server.NewSSEServer(
+			mcpServer,
+			server.WithSSEEndpoint("/sse"),
+			server.WithMessageEndpoint("/api/v1"),
+			server.WithKeepAlive(true),
+			server.WithBaseURL(opts.baseURL),
+			server.WithUseFullURLForMessageEndpoint(true),
+		)
+		log.Printf("SSE path: %s", sseServer.CompleteSsePath())
+		log.Printf("Message path: %s", sseServer.CompleteMessagePath())
+		mux.Handle("/sse", sseServer)
+		mux.Handle("/api/v1", sseServer)
+	}
+
+	var handler http.Handler = mux
+	if opts.authenticator != nil {
+		handler = auth.Middleware(opts.authenticator, opts.mapping, sessions.RequireOwner(withTrinoUser(handler)))
+	}
+	return cors.NewPolicy(opts.origins).Handler(handler)
+}
+
+// newAuthenticator builds the configured authentication methods and the mapping from
+// principals to Trino users
+func newAuthenticator(cfg *config.AuthConfig) (auth.Authenticator, *auth.UserMapping, error) {
+	var authenticators auth.Authenticators
+	if cfg.APIKeysFile != "" {
+		keys, err := auth.LoadAPIKeys(cfg.APIKeysFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		authenticators = append(authenticators, keys)
+		log.Println("API key authentication enabled")
+	}
+	if cfg.HMACSecretFile != "" {
+		verifier, err := auth.NewHMACVerifier(auth.HMACConfig{
+			SecretFile: cfg.HMACSecretFile,
+			Issuer:     cfg.Issuer,
+			Audience:   cfg.Audience,
+			UserClaim:  cfg.UserClaim,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		authenticators = append(authenticators, verifier)
+		log.Printf("HMAC token authentication enabled (user claim %q)", cfg.UserClaim)
+	}
+	if cfg.JWKSFile != "" {
+		verifier, err := auth.NewJWTVerifier(auth.JWTConfig{
+			JWKSFile:  cfg.JWKSFile,
+			Issuer:    cfg.Issuer,
+			Audience:  cfg.Audience,
+			UserClaim: cfg.UserClaim,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		authenticators = append(authenticators, verifier)
+		log.Printf("JWT authentication enabled (user claim %q)", cfg.UserClaim)
+	}
+
+	var mapping *auth.UserMapping
+	if cfg.UserMappingFile != "" {
+		var err error
+		if mapping, err = auth.LoadUserMapping(cfg.UserMappingFile); err != nil {
+			return nil, nil, err
+		}
+	}
+	return authenticators, mapping, nil
+}
+
+// withTrinoUser runs the queries of an authenticated request as the caller's Trino user
+func withTrinoUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if identity, ok := auth.IdentityFromContext(r.Context()); ok {
+			r = r.WithContext(trino.WithUser(r.Context(), identity.User))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func handleTrinoQuery(w http.ResponseWriter, r *http.Request, clusters *trino.Clusters, defaultFormat string) {
+	if clusters == nil {
 		http.Error(w, "Trino client not available", http.StatusServiceUnavailable)
 		return
 	}
 	var req struct {
-		Query string `json:"query"`
+		Query   string `json:"query"`
+		Format  string `json:"format"`
+		Cluster string `json:"cluster"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
-	res, err := client.ExecuteQuery(req.Query)
+	client, err := clusters.Get(req.Cluster)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// The format may be given in the body or as ?format=
+	formatName := req.Format
+	if formatName == "" {
+		formatName = r.URL.Query().Get("format")
+	}
+	if formatName == "" {
+		formatName = defaultFormat
+	}
+	enc, err := format.Get(formatName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res, err := client.ExecuteQuery(r.Context(), req.Query)
+	if errors.Is(err, trino.ErrShuttingDown) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	var violation *trino.PolicyViolationError
+	if errors.As(err, &violation) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(violation)
+		return
+	}
+	var costErr *trino.CostLimitError
+	if errors.As(err, &costErr) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_ = json.NewEncoder(w).Encode(costErr)
+		return
+	}
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Query failed: %v", err), http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(res)
+	// Row count and truncation are also reported in headers for formats that cannot carry them
+	w.Header().Set("Content-Type", enc.ContentType())
+	w.Header().Set("X-Result-Row-Count", strconv.Itoa(res.RowCount))
+	w.Header().Set("X-Result-Truncated", strconv.FormatBool(res.Truncated))
+	if err := enc.Encode(w, res); err != nil {
+		log.Printf("Error encoding query result: %v", err)
+	}
 }
 
 func registerTrinoTools(m *server.MCPServer, h *handlers.TrinoHandlers) {
+	cluster := clusterOption(h.Clusters)
 	m.AddTool(mcp.NewTool("execute_query",
-		mcp.WithDescription("Execute a SQL query"),
+		mcp.WithDescription("Execute a SQL query. Large results are paginated: use fetch_results with the returned handle and next_page_token"),
 		mcp.WithString("query", mcp.Required(), mcp.Description("SQL query")),
+		mcp.WithNumber("page_size", mcp.Description("Rows per page (capped by the server maximum)")),
+		formatOption(),
+		cluster,
 	), h.ExecuteQuery)
-	m.AddTool(mcp.NewTool("list_catalogs", mcp.WithDescription("List catalogs")), h.ListCatalogs)
+	m.AddTool(mcp.NewTool("fetch_results",
+		mcp.WithDescription("Fetch the next page of a paginated query result"),
+		mcp.WithString("handle", mcp.Required(), mcp.Description("Result handle returned by execute_query")),
+		mcp.WithString("page_token", mcp.Required(), mcp.Description("next_page_token from the previous page")),
+		mcp.WithNumber("page_size", mcp.Description("Rows per page (capped by the server maximum)")),
+		formatOption(),
+		cluster,
+	), h.FetchResults)
+	m.AddTool(mcp.NewTool("estimate_query_cost",
+		mcp.WithDescription("Estimate the rows and bytes a query reads from each table and the rows it produces, "+
+			"using the planner's EXPLAIN (TYPE IO) statistics, without running it. Reports the configured cost limits it exceeds"),
+		mcp.WithString("query", mcp.Required(), mcp.Description("SQL query")),
+		cluster,
+	), h.EstimateQueryCost)
+	m.AddTool(mcp.NewTool("submit_query",
+		mcp.WithDescription("Submit a long-running SQL query for background execution. Returns a job ID immediately; "+
+			"poll get_query_job for progress and the result"),
+		mcp.WithString("query", mcp.Required(), mcp.Description("SQL query")),
+		mcp.WithNumber("page_size", mcp.Description("Rows in the first result page (capped by the server maximum)")),
+		cluster,
+	), h.SubmitQuery)
+	m.AddTool(mcp.NewTool("get_query_job",
+		mcp.WithDescription("Get the state and progress of a submitted query and, once it has succeeded, "+
+			"the first page of its result. Further pages are read with fetch_results"),
+		mcp.WithString("job_id", mcp.Required(), mcp.Description("Job ID returned by submit_query")),
+		formatOption(),
+		cluster,
+	), h.GetQueryJob)
+	m.AddTool(mcp.NewTool("query_status",
+		mcp.WithDescription("Show the state, elapsed time, rows processed and bytes scanned of a query, "+
+			"or list the queries this server is running when no query ID is given"),
+		mcp.WithString("query_id", mcp.Description("Trino query ID")),
+		cluster,
+	), h.QueryStatus)
+	m.AddTool(mcp.NewTool("kill_query",
+		mcp.WithDescription("Cancel a running query started by this server"),
+		mcp.WithString("query_id", mcp.Required(), mcp.Description("Trino query ID, as listed by query_status")),
+		cluster,
+	), h.KillQuery)
+	m.AddTool(mcp.NewTool("list_clusters",
+		mcp.WithDescription("List the configured Trino clusters and check their health")), h.ListClusters)
+	m.AddTool(mcp.NewTool("list_catalogs", mcp.WithDescription("List catalogs"), cluster), h.ListCatalogs)
 	m.AddTool(mcp.NewTool("list_schemas",
 		mcp.WithDescription("List schemas"),
-		mcp.WithString("catalog", mcp.Description("Catalog"))), h.ListSchemas)
+		mcp.WithString("catalog", mcp.Description("Catalog")),
+		cluster), h.ListSchemas)
 	m.AddTool(mcp.NewTool("list_tables",
 		mcp.WithDescription("List tables"),
 		mcp.WithString("catalog", mcp.Description("Catalog")),
-		mcp.WithString("schema", mcp.Description("Schema"))), h.ListTables)
+		mcp.WithString("schema", mcp.Description("Schema")),
+		cluster), h.ListTables)
 	m.AddTool(mcp.NewTool("get_table_schema",
-		mcp.WithDescription("Get table schema"),
+		mcp.WithDescription("Get the columns of a table with their types and comments, and its partitioning. "+
+			"Optionally include the table comment, the CREATE statement with the table properties it declares "+
+			"(format, partitioning, bucketing), and the connector's $properties and $partitions metadata tables"),
 		mcp.WithString("catalog", mcp.Description("Catalog")),
 		mcp.WithString("schema", mcp.Description("Schema")),
-		mcp.WithString("table", mcp.Required(), mcp.Description("Table"))), h.GetTableSchema)
+		mcp.WithString("table", mcp.Required(),
+			mcp.Description("Table, optionally qualified as schema.table or catalog.schema.table")),
+		mcp.WithArray("include", mcp.Description("Optional parts to include"),
+			mcp.WithStringEnumItems([]string{trino.IncludeComment, trino.IncludeDDL, trino.IncludeProperties, trino.IncludePartitions})),
+		cluster), h.GetTableSchema)
+	m.AddTool(mcp.NewTool("profile_table",
+		mcp.WithDescription("Profile the value distribution of a table's columns before writing a query: the null fraction, "+
+			"distinct count and low and high values from SHOW STATS, and the null fraction, distinct count, min, max, "+
+			"most frequent values and sample values of a TABLESAMPLE sample of rows"),
+		mcp.WithString("catalog", mcp.Description("Catalog")),
+		mcp.WithString("schema", mcp.Description("Schema")),
+		mcp.WithString("table", mcp.Required(),
+			mcp.Description("Table, optionally qualified as schema.table or catalog.schema.table")),
+		mcp.WithArray("columns", mcp.Description("Columns to profile (default: all, at most 50)"), mcp.WithStringItems()),
+		mcp.WithNumber("sample_percent", mcp.Description("Percentage of rows sampled with TABLESAMPLE BERNOULLI "+
+			"(default: derived from the row count statistic)")),
+		mcp.WithNumber("sample_rows", mcp.Description("Maximum rows sampled (default 1000, at most 10000)")),
+		mcp.WithNumber("top_n", mcp.Description("Most frequent values reported per column (default 5, at most 20)")),
+		cluster), h.ProfileTable)
+	m.AddTool(mcp.NewTool("refresh_metadata",
+		mcp.WithDescription("Invalidate cached catalog, schema and table listings and table schemas, e.g. after creating "+
+			"or altering a table. Invalidates everything on the path to and below the named catalog, schema or table; "+
+			"with no names, the whole cache. Refreshes every cluster unless one is named, and reports the cache hit and miss counters"),
+		mcp.WithString("catalog", mcp.Description("Catalog")),
+		mcp.WithString("schema", mcp.Description("Schema")),
+		mcp.WithString("table", mcp.Description("Table, optionally qualified as schema.table or catalog.schema.table")),
+		cluster,
+	), h.RefreshMetadata)
+	m.AddTool(mcp.NewTool("search_metadata",
+		mcp.WithDescription("Find tables and columns whose names or comments match a keyword, across one or all catalogs. "+
+			"Matches are ranked: exact names first, then name prefixes, names containing the keyword and comments"),
+		mcp.WithString("pattern", mcp.Required(),
+			mcp.Description("Case-insensitive keyword, e.g. email; * matches any characters and ? a single character")),
+		mcp.WithString("catalog", mcp.Description("Catalog to search (default: all catalogs except system)")),
+		mcp.WithString("schema", mcp.Description("Schema to search")),
+		mcp.WithString("kind", mcp.Description("Only return matching tables or columns"), mcp.Enum("table", "column")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of matches (default 50, at most 500)")),
+		cluster), h.SearchMetadata)
+}
+
+func registerTrinoResources(m *server.MCPServer, h *handlers.TrinoHandlers) {
+	cluster := h.Clusters.DefaultName()
+	m.AddResource(mcp.NewResource(handlers.CatalogsURI, "Trino catalogs",
+		mcp.WithResourceDescription(fmt.Sprintf("Catalogs of the default cluster %s with links to their schemas", cluster)),
+		mcp.WithMIMEType("application/json"),
+	), h.ReadCatalogs)
+	m.AddResourceTemplate(mcp.NewResourceTemplate(handlers.CatalogURITemplate, "Trino catalog",
+		mcp.WithTemplateDescription(fmt.Sprintf("Schemas of a catalog of the default cluster %s with links to their tables", cluster)),
+		mcp.WithTemplateMIMEType("application/json"),
+	), h.ReadCatalog)
+	m.AddResourceTemplate(mcp.NewResourceTemplate(handlers.SchemaURITemplate, "Trino schema",
+		mcp.WithTemplateDescription(fmt.Sprintf("Tables of a schema of the default cluster %s with links to their definitions", cluster)),
+		mcp.WithTemplateMIMEType("application/json"),
+	), h.ReadSchema)
+	m.AddResourceTemplate(mcp.NewResourceTemplate(handlers.TableURITemplate, "Trino table",
+		mcp.WithTemplateDescription(fmt.Sprintf("Table columns, comments and CREATE statement, from the default cluster %s", cluster)),
+		mcp.WithTemplateMIMEType("application/json"),
+	), h.ReadTable)
+}
+
+// clusterOption describes the cluster argument shared by the tools
+func clusterOption(clusters *trino.Clusters) mcp.ToolOption {
+	return mcp.WithString("cluster",
+		mcp.Description(fmt.Sprintf("Trino cluster to use (default %s)", clusters.DefaultName())),
+		mcp.Enum(clusters.Names()...),
+	)
+}
+
+// formatOption describes the result format argument shared by the query tools
+func formatOption() mcp.ToolOption {
+	return mcp.WithString("format",
+		mcp.Description("Result format. markdown, csv, tsv, jsonl and columns are more compact than json; "+
+			"their column types and paging details follow in a second JSON block"),
+		mcp.Enum(format.Names()...),
+	)
 }
 
-func handleSignals(done chan<- bool) {
-	ch := make(chan os.Signal, 1)
-	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
-	<-ch
-	done <- true
+// shutdown refuses new tool calls and drains the Trino clients, cancelling queries
+// still running after the grace period. It then gives the tool calls a moment to
+// deliver their results.
+func shutdown(calls *handlers.ToolCalls, clusters *trino.Clusters, gracePeriod time.Duration) {
+	log.Printf("Waiting up to %s for running queries...", gracePeriod)
+	calls.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancel()
+	if err := clusters.Shutdown(ctx); err != nil {
+		log.Printf("Error closing Trino clients: %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), toolCallWait)
+	defer cancel()
+	if err := calls.Wait(ctx); err != nil {
+		log.Printf("WARNING: Tool calls still running at shutdown: %v", err)
+	}
 }
 
 func handleStatus(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/tuannvm/mcp-trino/internal/auth"
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/format"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+func TestHTTPTransports(t *testing.T) {
+	coordinator := trinotest.NewServer()
+	defer coordinator.Close()
+	coordinator.SetResult("SELECT 1 AS one", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "one", Type: "integer"}},
+		Rows:    [][]interface{}{{1}},
+	})
+	clusters, err := trino.NewClusters(&config.ClustersConfig{
+		Default:  config.DefaultClusterName,
+		Clusters: []config.ClusterConfig{{Name: config.DefaultClusterName, TrinoConfig: coordinator.Config()}},
+	})
+	if err != nil {
+		t.Fatalf("NewClusters() error = %v", err)
+	}
+	defer clusters.Close()
+	keys, err := auth.NewAPIKeys([]auth.APIKey{{Name: "alice", Key: "alice-key"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials := map[string]string{auth.APIKeyHeader: "alice-key"}
+
+	tests := []struct {
+		transport string
+		endpoint  string
+		newClient func(url string) (*client.Client, error)
+	}{
+		{transportStreamable, streamablePath, func(url string) (*client.Client, error) {
+			return client.NewStreamableHttpClient(url+streamablePath, transport.WithHTTPHeaders(credentials))
+		}},
+		{transportSSE, "/sse", func(url string) (*client.Client, error) {
+			return client.NewSSEMCPClient(url+"/sse", transport.WithHeaders(credentials))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.transport, func(t *testing.T) {
+			mcpServer, sessions, _ := newMCPServer(clusters, format.JSON)
+			srv := httptest.NewServer(nil)
+			defer srv.Close()
+			srv.Config.Handler = newHTTPHandler(mcpServer, sessions, clusters, httpOptions{
+				transport:     tt.transport,
+				baseURL:       srv.URL,
+				resultFormat:  format.JSON,
+				authenticator: keys,
+			})
+
+			// The MCP endpoint shares the authentication of the other routes
+			resp, err := http.Post(srv.URL+tt.endpoint, "application/json", strings.NewReader(`{}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("unauthenticated POST %s = %d, want 401", tt.endpoint, resp.StatusCode)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			c, err := tt.newClient(srv.URL)
+			if err != nil {
+				t.Fatalf("creating client: %v", err)
+			}
+			defer c.Close()
+			if err := c.Start(ctx); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+
+			initRequest := mcp.InitializeRequest{}
+			initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+			initRequest.Params.ClientInfo = mcp.Implementation{Name: "mcp-trino-test", Version: "1.0"}
+			initResult, err := c.Initialize(ctx, initRequest)
+			if err != nil {
+				t.Fatalf("Initialize() error = %v", err)
+			}
+			if initResult.ServerInfo.Name != "Trino MCP Server" {
+				t.Errorf("server name = %q", initResult.ServerInfo.Name)
+			}
+
+			callRequest := mcp.CallToolRequest{}
+			callRequest.Params.Name = "execute_query"
+			callRequest.Params.Arguments = map[string]interface{}{"query": "SELECT 1 AS one"}
+			result, err := c.CallTool(ctx, callRequest)
+			if err != nil {
+				t.Fatalf("CallTool() error = %v", err)
+			}
+			if result.IsError || len(result.Content) == 0 {
+				t.Fatalf("CallTool() = %+v, want a result", result)
+			}
+			text, ok := result.Content[0].(mcp.TextContent)
+			if !ok {
+				t.Fatalf("CallTool() content = %T, want text", result.Content[0])
+			}
+			var page struct {
+				Rows [][]interface{} `json:"rows"`
+			}
+			if err := json.Unmarshal([]byte(text.Text), &page); err != nil {
+				t.Fatalf("decoding %s: %v", text.Text, err)
+			}
+			if len(page.Rows) != 1 || page.Rows[0][0] != float64(1) {
+				t.Errorf("rows = %v, want [[1]]", page.Rows)
+			}
+
+			// The query ran as the authenticated caller
+			requests := coordinator.Requests()
+			if got := requests[len(requests)-1].Header.Get("X-Trino-User"); got != "alice" {
+				t.Errorf("X-Trino-User = %q, want alice", got)
+			}
+		})
+	}
+}
+
+func TestProgressNotifications(t *testing.T) {
+	coordinator := trinotest.NewServer()
+	defer coordinator.Close()
+	coordinator.SetResult("SELECT count(*) FROM big", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "_col0", Type: "bigint"}},
+		Rows:    [][]interface{}{{42}},
+		Delay:   1500 * time.Millisecond,
+	})
+	clusters, err := trino.NewClusters(&config.ClustersConfig{
+		Default:  config.DefaultClusterName,
+		Clusters: []config.ClusterConfig{{Name: config.DefaultClusterName, TrinoConfig: coordinator.Config()}},
+	})
+	if err != nil {
+		t.Fatalf("NewClusters() error = %v", err)
+	}
+	defer clusters.Close()
+
+	t.Setenv("MCP_PROGRESS_INTERVAL", "1")
+	mcpServer, sessions, _ := newMCPServer(clusters, format.JSON)
+	srv := httptest.NewServer(nil)
+	defer srv.Close()
+	srv.Config.Handler = newHTTPHandler(mcpServer, sessions, clusters, httpOptions{
+		transport:    transportStreamable,
+		baseURL:      srv.URL,
+		resultFormat: format.JSON,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	c, err := client.NewStreamableHttpClient(srv.URL + streamablePath)
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	defer c.Close()
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	var mu sync.Mutex
+	var notifications []mcp.JSONRPCNotification
+	c.OnNotification(func(n mcp.JSONRPCNotification) {
+		if n.Method == "notifications/progress" {
+			mu.Lock()
+			notifications = append(notifications, n)
+			mu.Unlock()
+		}
+	})
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "mcp-trino-test", Version: "1.0"}
+	if _, err := c.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	callRequest := mcp.CallToolRequest{}
+	callRequest.Params.Name = "execute_query"
+	callRequest.Params.Arguments = map[string]interface{}{"query": "SELECT count(*) FROM big"}
+	callRequest.Params.Meta = &mcp.Meta{ProgressToken: "count-big"}
+	result, err := c.CallTool(ctx, callRequest)
+	if err != nil || result.IsError {
+		t.Fatalf("CallTool() = %+v, %v; want a result", result, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(notifications) == 0 {
+		t.Fatal("no progress notifications were sent for a running query")
+	}
+	for _, n := range notifications {
+		params := n.Params.AdditionalFields
+		message, _ := params["message"].(string)
+		if params["progressToken"] != "count-big" || !strings.Contains(message, "splits") {
+			t.Errorf("progress notification params = %v, want the request's token and a summary", params)
+		}
+	}
+}
@@ -47,6 +47,33 @@ func main() {
 	}
 }
 
+// queryResponse is the body returned by the /api/query endpoint
+type queryResponse struct {
+	Columns []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"columns"`
+	Rows      [][]interface{} `json:"rows"`
+	Truncated bool            `json:"truncated"`
+}
+
+// strings returns the string values of the named column
+func (r queryResponse) strings(name string) []string {
+	values := make([]string, 0, len(r.Rows))
+	for i, col := range r.Columns {
+		if col.Name != name {
+			continue
+		}
+		for _, row := range r.Rows {
+			if s, ok := row[i].(string); ok {
+				values = append(values, s)
+			}
+		}
+		break
+	}
+	return values
+}
+
 // executeQuery executes a SQL query against the Trino server
 func executeQuery(query string) (string, error) {
 	url := fmt.Sprintf("http://%s:%s/api/query", host, port)
@@ -89,20 +116,13 @@ func listCatalogs() ([]string, error) {
 		return nil, err
 	}
 
-	var data []map[string]interface{}
+	var data queryResponse
 	err = json.Unmarshal([]byte(result), &data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse catalog result: %v", err)
 	}
 
-	catalogs := make([]string, 0, len(data))
-	for _, row := range data {
-		if catalog, ok := row["Catalog"].(string); ok {
-			catalogs = append(catalogs, catalog)
-		}
-	}
-
-	return catalogs, nil
+	return data.strings("Catalog"), nil
 }
 
 // listSchemas lists all schemas in a catalog
@@ -113,20 +133,13 @@ func listSchemas(catalog string) ([]string, error) {
 		return nil, err
 	}
 
-	var data []map[string]interface{}
+	var data queryResponse
 	err = json.Unmarshal([]byte(result), &data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse schema result: %v", err)
 	}
 
-	schemas := make([]string, 0, len(data))
-	for _, row := range data {
-		if schema, ok := row["Schema"].(string); ok {
-			schemas = append(schemas, schema)
-		}
-	}
-
-	return schemas, nil
+	return data.strings("Schema"), nil
 }
 
 // escapeQuery escapes quotes in a SQL query
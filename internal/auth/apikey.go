@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// APIKeyHeader carries an API key; keys are also accepted as bearer tokens
+const APIKeyHeader = "X-API-Key"
+
+// APIKey is a static key from the API key file. Key holds the key itself or SHA256 its
+// hex-encoded SHA-256 digest, so the file need not contain the secret.
+type APIKey struct {
+	Name   string `yaml:"name" json:"name"`
+	Key    string `yaml:"key" json:"key"`
+	SHA256 string `yaml:"sha256" json:"sha256"`
+	// User, when set, is the Trino user the key's queries run as instead of Name
+	User string `yaml:"user" json:"user"`
+
+	digest []byte
+}
+
+// APIKeys authenticates callers by static API keys
+type APIKeys struct {
+	keys []APIKey
+}
+
+// LoadAPIKeys reads API keys from a YAML or JSON file
+func LoadAPIKeys(file string) (*APIKeys, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API key file: %w", err)
+	}
+	var f struct {
+		Keys []APIKey `yaml:"keys"`
+	}
+	// YAML is a superset of JSON, so one decoder handles both formats
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse API key file %s: %w", file, err)
+	}
+	keys, err := NewAPIKeys(f.Keys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API key file %s: %w", file, err)
+	}
+	return keys, nil
+}
+
+// NewAPIKeys validates keys and returns an authenticator accepting them
+func NewAPIKeys(keys []APIKey) (*APIKeys, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no API keys defined")
+	}
+	seen := make(map[string]string, len(keys))
+	for i := range keys {
+		k := &keys[i]
+		if k.Name == "" {
+			return nil, fmt.Errorf("key %d: name is required", i+1)
+		}
+		switch {
+		case k.Key != "" && k.SHA256 != "":
+			return nil, fmt.Errorf("key %q: set either key or sha256, not both", k.Name)
+		case k.Key != "":
+			sum := sha256.Sum256([]byte(k.Key))
+			k.digest = sum[:]
+		case k.SHA256 != "":
+			digest, err := hex.DecodeString(k.SHA256)
+			if err != nil || len(digest) != sha256.Size {
+				return nil, fmt.Errorf("key %q: sha256 must be a hex-encoded SHA-256 digest", k.Name)
+			}
+			k.digest = digest
+		default:
+			return nil, fmt.Errorf("key %q: key or sha256 is required", k.Name)
+		}
+		k.Key = ""
+		if other, ok := seen[string(k.digest)]; ok {
+			return nil, fmt.Errorf("keys %q and %q are identical", other, k.Name)
+		}
+		seen[string(k.digest)] = k.Name
+	}
+	return &APIKeys{keys: keys}, nil
+}
+
+// Authenticate accepts a request carrying a known key in the X-API-Key header or as a
+// bearer token
+func (a *APIKeys) Authenticate(r *http.Request) (Identity, error) {
+	key := strings.TrimSpace(r.Header.Get(APIKeyHeader))
+	if key == "" {
+		var ok bool
+		if key, ok = bearerToken(r); !ok {
+			return Identity{}, ErrNoCredentials
+		}
+	}
+
+	// Compare digests in constant time and without stopping at a match, so timing
+	// reveals neither the key nor which entry it matched
+	sum := sha256.Sum256([]byte(key))
+	var found *APIKey
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(sum[:], a.keys[i].digest) == 1 {
+			found = &a.keys[i]
+		}
+	}
+	if found == nil {
+		return Identity{}, errors.New("invalid API key")
+	}
+	return Identity{Principal: found.Name, User: found.User}, nil
+}
@@ -0,0 +1,318 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKeys writes a JWKS with an RSA and an EC key and returns the private keys
+func testKeys(t *testing.T) (string, *rsa.PrivateKey, *ecdsa.PrivateKey) {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b64 := func(n *big.Int) string { return base64.RawURLEncoding.EncodeToString(n.Bytes()) }
+	jwks := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64(rsaKey.N), "e": b64(big.NewInt(int64(rsaKey.E)))},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(ecKey.X), "y": b64(ecKey.Y)},
+		{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": b64(rsaKey.N), "e": "AQAB"},
+	}}
+	data, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return file, rsaKey, ecKey
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestJWTVerifier(t *testing.T) {
+	file, rsaKey, ecKey := testKeys(t)
+	verifier, err := NewJWTVerifier(JWTConfig{
+		JWKSFile:  file,
+		Issuer:    "https://idp.example.com",
+		Audience:  "mcp-trino",
+		UserClaim: "email",
+	})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier() error = %v", err)
+	}
+
+	claims := func(overrides jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"iss":   "https://idp.example.com",
+			"aud":   "mcp-trino",
+			"sub":   "1234",
+			"email": "alice@example.com",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"RSA", sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(nil)), false},
+		{"EC", sign(t, jwt.SigningMethodES256, "ec-1", ecKey, claims(nil)), false},
+		{"expired", sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})), true},
+		{"no expiry", sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"exp": nil})), true},
+		{"wrong issuer", sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"iss": "https://evil.example.com"})), true},
+		{"wrong audience", sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"aud": "other"})), true},
+		{"missing user claim", sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"email": nil})), true},
+		{"unknown key", sign(t, jwt.SigningMethodRS256, "rsa-2", rsaKey, claims(nil)), true},
+		{"encryption key", sign(t, jwt.SigningMethodRS256, "enc-1", rsaKey, claims(nil)), true},
+		{"key type mismatch", sign(t, jwt.SigningMethodES256, "rsa-1", ecKey, claims(nil)), true},
+		{"HMAC", sign(t, jwt.SigningMethodHS256, "rsa-1", []byte("secret"), claims(nil)), true},
+		{"garbage", "not-a-token", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := verifier.Verify(tt.token)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Verify() = %+v, want error", identity)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if identity.Principal != "alice@example.com" {
+				t.Errorf("Verify() = %+v, want principal alice@example.com", identity)
+			}
+		})
+	}
+}
+
+func TestUserMapping(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "mapping.yaml")
+	data := `
+rules:
+  - pattern: "admin@example\\.com"
+    allow: false
+  - pattern: "(.*)@example\\.com"
+  - pattern: "svc-(.*)"
+    user: "service_$1"
+`
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	mapping, err := LoadUserMapping(file)
+	if err != nil {
+		t.Fatalf("LoadUserMapping() error = %v", err)
+	}
+
+	tests := []struct {
+		principal string
+		want      string
+		wantErr   bool
+	}{
+		{"alice@example.com", "alice", false},
+		{"svc-etl", "service_etl", false},
+		{"admin@example.com", "", true},
+		// Patterns must match the whole principal
+		{"alice@example.com.evil.org", "", true},
+		{"bob@other.org", "", true},
+	}
+	for _, tt := range tests {
+		got, err := mapping.Map(tt.principal)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Map(%q) = %q, %v; want %q, error %v", tt.principal, got, err, tt.want, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrUserNotAllowed) {
+			t.Errorf("Map(%q) error = %v, want ErrUserNotAllowed", tt.principal, err)
+		}
+	}
+
+	var unmapped *UserMapping
+	if got, err := unmapped.Map("anyone"); err != nil || got != "anyone" {
+		t.Errorf("nil mapping Map() = %q, %v; want the principal", got, err)
+	}
+}
+
+func TestHMACVerifier(t *testing.T) {
+	secret := strings.Repeat("s", minHMACSecretLength)
+	file := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(file, []byte(secret+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := NewHMACVerifier(HMACConfig{SecretFile: file, Audience: "mcp-trino"})
+	if err != nil {
+		t.Fatalf("NewHMACVerifier() error = %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := jwt.MapClaims{"sub": "etl", "aud": "mcp-trino", "exp": time.Now().Add(time.Hour).Unix()}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"HS256", sign(t, jwt.SigningMethodHS256, "", []byte(secret), claims), false},
+		{"HS512", sign(t, jwt.SigningMethodHS512, "", []byte(secret), claims), false},
+		{"wrong secret", sign(t, jwt.SigningMethodHS256, "", []byte(strings.Repeat("x", minHMACSecretLength)), claims), true},
+		{"asymmetric", sign(t, jwt.SigningMethodRS256, "", rsaKey, claims), true},
+		{"wrong audience", sign(t, jwt.SigningMethodHS256, "", []byte(secret), jwt.MapClaims{"sub": "etl", "exp": time.Now().Add(time.Hour).Unix()}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := verifier.Verify(tt.token)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Verify() = %+v, want error", identity)
+				}
+				return
+			}
+			if err != nil || identity.Principal != "etl" {
+				t.Errorf("Verify() = %+v, %v; want principal etl", identity, err)
+			}
+		})
+	}
+
+	short := filepath.Join(t.TempDir(), "short")
+	if err := os.WriteFile(short, []byte("too-short"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewHMACVerifier(HMACConfig{SecretFile: short}); err == nil {
+		t.Error("NewHMACVerifier() accepted a short secret")
+	}
+}
+
+func TestLoadAPIKeys(t *testing.T) {
+	sum := sha256.Sum256([]byte("hashed-key"))
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"plain and hashed", "keys:\n  - {name: a, key: plain-key}\n  - {name: b, sha256: " + hex.EncodeToString(sum[:]) + "}\n", false},
+		{"empty", "keys: []\n", true},
+		{"missing name", "keys:\n  - {key: plain-key}\n", true},
+		{"missing key", "keys:\n  - {name: a}\n", true},
+		{"both", "keys:\n  - {name: a, key: k, sha256: " + hex.EncodeToString(sum[:]) + "}\n", true},
+		{"bad digest", "keys:\n  - {name: a, sha256: abc}\n", true},
+		{"duplicate", "keys:\n  - {name: a, key: k}\n  - {name: b, key: k}\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "keys.yaml")
+			if err := os.WriteFile(file, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadAPIKeys(file)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadAPIKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	file, rsaKey, _ := testKeys(t)
+	verifier, err := NewJWTVerifier(JWTConfig{JWKSFile: file})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier() error = %v", err)
+	}
+	keys, err := NewAPIKeys([]APIKey{
+		{Name: "etl-job", Key: "etl-key", User: "etl"},
+		{Name: "bob@example.com", Key: "bob-key"},
+		{Name: "root", Key: "root-key"},
+	})
+	if err != nil {
+		t.Fatalf("NewAPIKeys() error = %v", err)
+	}
+	deny := false
+	mapping := &UserMapping{Rules: []MappingRule{
+		{Pattern: "root", Allow: &deny},
+		{Pattern: "(.*)@example\\.com"},
+		{Pattern: "(.*)"},
+	}}
+	if err := mapping.compile(); err != nil {
+		t.Fatal(err)
+	}
+	handler := Middleware(Authenticators{keys, verifier}, mapping, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ := IdentityFromContext(r.Context())
+		_, _ = w.Write([]byte(identity.User))
+	}))
+	valid := sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()})
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		header   string
+		value    string
+		wantCode int
+		wantBody string
+	}{
+		{"status page is public", http.MethodGet, "/", "", "", http.StatusOK, ""},
+		{"missing credentials", http.MethodGet, "/sse", "", "", http.StatusUnauthorized, ""},
+		{"invalid token", http.MethodPost, "/api/query", "Authorization", "Bearer nope", http.StatusUnauthorized, ""},
+		{"wrong scheme", http.MethodPost, "/api/query", "Authorization", "Basic " + valid, http.StatusUnauthorized, ""},
+		{"valid token", http.MethodPost, "/api/query", "Authorization", "Bearer " + valid, http.StatusOK, "alice"},
+		{"API key header", http.MethodPost, "/api/v1", APIKeyHeader, "etl-key", http.StatusOK, "etl"},
+		{"API key as bearer token", http.MethodGet, "/sse", "Authorization", "Bearer bob-key", http.StatusOK, "bob"},
+		{"unknown API key", http.MethodGet, "/sse", APIKeyHeader, "guess", http.StatusUnauthorized, ""},
+		{"rejected principal", http.MethodGet, "/sse", APIKeyHeader, "root-key", http.StatusForbidden, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
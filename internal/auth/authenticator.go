@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrNoCredentials is returned by an Authenticator when a request carries none of the
+// credentials it understands
+var ErrNoCredentials = errors.New("no credentials")
+
+// Authenticator identifies the caller of an HTTP request
+type Authenticator interface {
+	// Authenticate returns the caller's identity. User may be left empty for the
+	// middleware to derive from the principal.
+	Authenticate(r *http.Request) (Identity, error)
+}
+
+// Authenticators accepts a request if any of its authenticators does. They are tried
+// in order; a rejected principal ends the search.
+type Authenticators []Authenticator
+
+// Authenticate returns the identity from the first authenticator that accepts the request
+func (a Authenticators) Authenticate(r *http.Request) (Identity, error) {
+	err := ErrNoCredentials
+	for _, authenticator := range a {
+		identity, authErr := authenticator.Authenticate(r)
+		switch {
+		case authErr == nil:
+			return identity, nil
+		case errors.Is(authErr, ErrUserNotAllowed):
+			return Identity{}, authErr
+		case !errors.Is(authErr, ErrNoCredentials):
+			err = authErr
+		}
+	}
+	return Identity{}, err
+}
@@ -0,0 +1,25 @@
+// Package auth authenticates callers of the HTTP transport and maps them to Trino users.
+package auth
+
+import "context"
+
+// Identity is an authenticated caller
+type Identity struct {
+	// Principal is the name the caller authenticated as, e.g. the token's user claim
+	Principal string
+	// User is the Trino user the caller's queries run as
+	User string
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a context carrying the caller's identity
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the caller's identity, if the request was authenticated
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
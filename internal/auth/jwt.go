@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwksReloadInterval limits how often an unknown key ID triggers a reload of the JWKS file
+const jwksReloadInterval = time.Minute
+
+// JWTConfig configures bearer token verification
+type JWTConfig struct {
+	// JWKSFile is a local JSON Web Key Set holding the issuer's signing keys
+	JWKSFile string
+	// Issuer and Audience, when set, must match the token's iss and aud claims
+	Issuer   string
+	Audience string
+	// UserClaim names the claim holding the caller's principal, "sub" by default
+	UserClaim string
+}
+
+// JWTVerifier validates signed JWT bearer tokens against a local JWKS
+type JWTVerifier struct {
+	config JWTConfig
+	parser *jwt.Parser
+
+	mu         sync.Mutex
+	keys       map[string]crypto.PublicKey
+	lastLoaded time.Time
+}
+
+// NewJWTVerifier loads the key set and returns a verifier
+func NewJWTVerifier(cfg JWTConfig) (*JWTVerifier, error) {
+	if cfg.UserClaim == "" {
+		cfg.UserClaim = "sub"
+	}
+	methods := []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+	v := &JWTVerifier{config: cfg, parser: tokenParser(methods, cfg.Issuer, cfg.Audience)}
+	keys, err := loadJWKS(cfg.JWKSFile)
+	if err != nil {
+		return nil, err
+	}
+	v.keys, v.lastLoaded = keys, time.Now()
+	return v, nil
+}
+
+// Authenticate verifies the request's bearer token
+func (v *JWTVerifier) Authenticate(r *http.Request) (Identity, error) {
+	token, ok := bearerToken(r)
+	if !ok {
+		return Identity{}, ErrNoCredentials
+	}
+	return v.Verify(token)
+}
+
+// Verify validates a token and returns the caller's identity
+func (v *JWTVerifier) Verify(token string) (Identity, error) {
+	return verifyToken(v.parser, token, v.key, v.config.UserClaim)
+}
+
+// key selects the verification key by the token's key ID. An unknown ID reloads the
+// key set, at most once per jwksReloadInterval, so rotated keys are picked up.
+func (v *JWTVerifier) key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if key, ok := v.lookupLocked(kid); ok {
+		return key, nil
+	}
+	if time.Since(v.lastLoaded) >= jwksReloadInterval {
+		v.lastLoaded = time.Now()
+		keys, err := loadJWKS(v.config.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		v.keys = keys
+		if key, ok := v.lookupLocked(kid); ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupLocked finds a key by ID; a token without an ID may use the only key in the set
+func (v *JWTVerifier) lookupLocked(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// tokenParser returns a parser accepting the given signing methods that requires an
+// expiry and, when set, the issuer and audience
+func tokenParser(methods []string, issuer, audience string) *jwt.Parser {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if issuer != "" {
+		opts = append(opts, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	}
+	return jwt.NewParser(opts...)
+}
+
+// verifyToken validates a token and reads the caller's principal from userClaim
+func verifyToken(parser *jwt.Parser, token string, key jwt.Keyfunc, userClaim string) (Identity, error) {
+	claims := jwt.MapClaims{}
+	if _, err := parser.ParseWithClaims(token, claims, key); err != nil {
+		return Identity{}, fmt.Errorf("invalid token: %w", err)
+	}
+	principal, _ := claims[userClaim].(string)
+	if principal == "" {
+		return Identity{}, fmt.Errorf("invalid token: missing %q claim", userClaim)
+	}
+	return Identity{Principal: principal}, nil
+}
+
+// jsonWebKey is a public key in JWK format (RFC 7517)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// loadJWKS reads the signing keys of a JSON Web Key Set file
+func loadJWKS(file string) (map[string]crypto.PublicKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS file %s: %w", file, err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for i, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("JWKS file %s: key %d (%q): %w", file, i+1, jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS file %s holds no signing keys", file)
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("invalid base64url key parameter")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
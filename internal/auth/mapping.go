@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUserNotAllowed is returned when no mapping rule admits a principal
+var ErrUserNotAllowed = errors.New("principal is not allowed")
+
+// UserMapping maps authenticated principals to Trino users. It follows the format of
+// Trino's user mapping file: rules are tried in order and the first whose pattern
+// matches the whole principal decides.
+type UserMapping struct {
+	Rules []MappingRule `yaml:"rules" json:"rules"`
+}
+
+// MappingRule maps principals matching Pattern to User, which may refer to capture
+// groups ("$1" by default). A rule with Allow set to false rejects the principal.
+type MappingRule struct {
+	Pattern string `yaml:"pattern" json:"pattern"`
+	User    string `yaml:"user" json:"user"`
+	Allow   *bool  `yaml:"allow" json:"allow"`
+
+	re *regexp.Regexp
+}
+
+// LoadUserMapping reads a mapping from a YAML or JSON file
+func LoadUserMapping(file string) (*UserMapping, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user mapping file: %w", err)
+	}
+	var m UserMapping
+	// YAML is a superset of JSON, so one decoder handles both formats
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse user mapping file %s: %w", file, err)
+	}
+	if err := m.compile(); err != nil {
+		return nil, fmt.Errorf("invalid user mapping file %s: %w", file, err)
+	}
+	return &m, nil
+}
+
+func (m *UserMapping) compile() error {
+	for i := range m.Rules {
+		r := &m.Rules[i]
+		re, err := regexp.Compile(`^(?:` + r.Pattern + `)$`)
+		if err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		r.re = re
+		if r.User == "" {
+			r.User = "$1"
+		}
+	}
+	return nil
+}
+
+// Map returns the Trino user for a principal. A nil mapping uses the principal as is.
+func (m *UserMapping) Map(principal string) (string, error) {
+	if m == nil {
+		return principal, nil
+	}
+	for _, r := range m.Rules {
+		match := r.re.FindStringSubmatchIndex(principal)
+		if match == nil {
+			continue
+		}
+		if r.Allow != nil && !*r.Allow {
+			return "", fmt.Errorf("%w: %s", ErrUserNotAllowed, principal)
+		}
+		user := string(r.re.ExpandString(nil, r.User, principal, match))
+		if user == "" {
+			return "", fmt.Errorf("%w: %s maps to an empty user", ErrUserNotAllowed, principal)
+		}
+		return user, nil
+	}
+	return "", fmt.Errorf("%w: %s matches no mapping rule", ErrUserNotAllowed, principal)
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Middleware requires every request except the status page (GET /) and CORS preflights
+// to authenticate, and stores the caller's identity in the request context. Callers
+// whose authenticator sets no Trino user are mapped to one by mapping.
+func Middleware(authenticator Authenticator, mapping *UserMapping, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions || (r.Method == http.MethodGet && r.URL.Path == "/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		identity, err := authenticator.Authenticate(r)
+		if err == nil && identity.User == "" {
+			identity.User, err = mapping.Map(identity.Principal)
+		}
+		switch {
+		case err == nil:
+			next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), identity)))
+		case errors.Is(err, ErrNoCredentials):
+			w.Header().Set("WWW-Authenticate", `Bearer`)
+			http.Error(w, "missing credentials", http.StatusUnauthorized)
+		case errors.Is(err, ErrUserNotAllowed):
+			log.Printf("Rejected request to %s: %v", r.URL.Path, err)
+			http.Error(w, "user is not allowed", http.StatusForbidden)
+		default:
+			log.Printf("Rejected request to %s: %v", r.URL.Path, err)
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		}
+	})
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	token = strings.TrimSpace(token)
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
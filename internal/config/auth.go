@@ -0,0 +1,30 @@
+package config
+
+// AuthConfig configures caller authentication on the HTTP transport
+type AuthConfig struct {
+	APIKeysFile     string // Optional YAML/JSON file of static API keys
+	HMACSecretFile  string // Optional shared secret for HS256/384/512-signed tokens
+	JWKSFile        string // Optional local JWKS holding the token issuer's signing keys
+	Issuer          string // Required token issuer, if set
+	Audience        string // Required token audience, if set
+	UserClaim       string // Claim holding the caller's principal
+	UserMappingFile string // Optional YAML/JSON rules mapping principals to Trino users
+}
+
+// NewAuthConfig creates a new AuthConfig with values from environment variables or defaults
+func NewAuthConfig() *AuthConfig {
+	return &AuthConfig{
+		APIKeysFile:     getEnv("MCP_AUTH_API_KEYS_FILE", ""),
+		HMACSecretFile:  getEnv("MCP_AUTH_HMAC_SECRET_FILE", ""),
+		JWKSFile:        getEnv("MCP_AUTH_JWKS_FILE", ""),
+		Issuer:          getEnv("MCP_AUTH_ISSUER", ""),
+		Audience:        getEnv("MCP_AUTH_AUDIENCE", ""),
+		UserClaim:       getEnv("MCP_AUTH_USER_CLAIM", "sub"),
+		UserMappingFile: getEnv("MCP_AUTH_USER_MAPPING_FILE", ""),
+	}
+}
+
+// Enabled reports whether callers must authenticate, i.e. any method is configured
+func (c *AuthConfig) Enabled() bool {
+	return c.APIKeysFile != "" || c.HMACSecretFile != "" || c.JWKSFile != ""
+}
@@ -0,0 +1,302 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultClusterName names the single cluster configured by the TRINO_* variables alone
+const DefaultClusterName = "default"
+
+// ClusterConfig is the configuration of one named Trino cluster
+type ClusterConfig struct {
+	Name string
+	*TrinoConfig
+}
+
+// ClustersConfig lists the configured clusters and the one used when a call names none
+type ClustersConfig struct {
+	Default  string
+	Clusters []ClusterConfig
+}
+
+// clusterSettings override the TRINO_* settings for one cluster; unset fields inherit them
+type clusterSettings struct {
+	Name              string  `yaml:"name"`
+	Host              *string `yaml:"host"`
+	Port              *int    `yaml:"port"`
+	User              *string `yaml:"user"`
+	Password          *string `yaml:"password"`
+	Catalog           *string `yaml:"catalog"`
+	Schema            *string `yaml:"schema"`
+	Scheme            *string `yaml:"scheme"`
+	SSL               *bool   `yaml:"ssl"`
+	SSLInsecure       *bool   `yaml:"ssl_insecure"`
+	Auth              *string `yaml:"auth"`
+	AccessToken       *string `yaml:"access_token"`
+	AccessTokenFile   *string `yaml:"access_token_file"`
+	KerberosKeytab    *string `yaml:"kerberos_keytab"`
+	KerberosPrincipal *string `yaml:"kerberos_principal"`
+	KerberosRealm     *string `yaml:"kerberos_realm"`
+	KerberosConfig    *string `yaml:"kerberos_config"`
+	KerberosService   *string `yaml:"kerberos_service_name"`
+	ClientCert        *string `yaml:"client_cert"`
+	ClientKey         *string `yaml:"client_key"`
+	CAFile            *string `yaml:"ca_file"`
+	TLSServerName     *string `yaml:"tls_server_name"`
+	TLSMinVersion     *string `yaml:"tls_min_version"`
+	AllowWriteQueries *bool   `yaml:"allow_write_queries"`
+	PolicyFile        *string `yaml:"policy_file"`
+	QueryTimeout      *int    `yaml:"query_timeout"` // seconds
+	JobTimeout        *int    `yaml:"job_timeout"`   // seconds
+}
+
+// clustersFile is the layout of TRINO_CLUSTERS_FILE
+type clustersFile struct {
+	Default  string            `yaml:"default"`
+	Clusters []clusterSettings `yaml:"clusters"`
+}
+
+var clusterNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// NewClustersConfig reads the cluster registry from the YAML or JSON file named by
+// TRINO_CLUSTERS_FILE, or else from indexed TRINO_CLUSTER_<n>_* variables. Without
+// either, the TRINO_* variables configure a single cluster named "default".
+func NewClustersConfig() (*ClustersConfig, error) {
+	base := NewTrinoConfig()
+	defaultCluster := getEnv("TRINO_DEFAULT_CLUSTER", "")
+	production := strings.EqualFold(getEnv("MCP_PROFILE", ""), ProfileProduction)
+
+	var settings []clusterSettings
+	if file := getEnv("TRINO_CLUSTERS_FILE", ""); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read clusters file: %w", err)
+		}
+		var f clustersFile
+		// YAML is a superset of JSON, so one decoder handles both formats
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse clusters file %s: %w", file, err)
+		}
+		if len(f.Clusters) == 0 {
+			return nil, fmt.Errorf("clusters file %s defines no clusters", file)
+		}
+		settings = f.Clusters
+		if defaultCluster == "" {
+			defaultCluster = f.Default
+		}
+	} else {
+		var err error
+		if settings, err = clustersFromEnv(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(settings) == 0 {
+		if defaultCluster != "" && defaultCluster != DefaultClusterName {
+			return nil, fmt.Errorf("TRINO_DEFAULT_CLUSTER %q is not a configured cluster", defaultCluster)
+		}
+		if err := base.Validate(); err != nil {
+			return nil, err
+		}
+		if err := base.checkInsecureTLS(DefaultClusterName, production); err != nil {
+			return nil, err
+		}
+		return &ClustersConfig{
+			Default:  DefaultClusterName,
+			Clusters: []ClusterConfig{{Name: DefaultClusterName, TrinoConfig: base}},
+		}, nil
+	}
+
+	cfg := &ClustersConfig{Default: defaultCluster}
+	seen := make(map[string]bool)
+	for _, s := range settings {
+		if !clusterNamePattern.MatchString(s.Name) {
+			return nil, fmt.Errorf("invalid cluster name %q: use letters, digits, '-' and '_'", s.Name)
+		}
+		if seen[s.Name] {
+			return nil, fmt.Errorf("duplicate cluster name %q", s.Name)
+		}
+		seen[s.Name] = true
+
+		trinoConfig, err := s.apply(base)
+		if err == nil {
+			err = trinoConfig.Validate()
+		}
+		if err == nil {
+			err = trinoConfig.checkInsecureTLS(s.Name, production)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", s.Name, err)
+		}
+		cfg.Clusters = append(cfg.Clusters, ClusterConfig{Name: s.Name, TrinoConfig: trinoConfig})
+	}
+
+	if cfg.Default == "" {
+		cfg.Default = cfg.Clusters[0].Name
+	}
+	if !seen[cfg.Default] {
+		return nil, fmt.Errorf("default cluster %q is not a configured cluster", cfg.Default)
+	}
+	return cfg, nil
+}
+
+// clustersFromEnv reads TRINO_CLUSTER_1_*, TRINO_CLUSTER_2_*, ... up to the first index without a NAME
+func clustersFromEnv() ([]clusterSettings, error) {
+	var settings []clusterSettings
+	for n := 1; ; n++ {
+		prefix := fmt.Sprintf("TRINO_CLUSTER_%d_", n)
+		name, ok := os.LookupEnv(prefix + "NAME")
+		if !ok {
+			return settings, nil
+		}
+
+		s := clusterSettings{
+			Name:       name,
+			Host:       lookupEnv(prefix + "HOST"),
+			User:       lookupEnv(prefix + "USER"),
+			Password:   lookupEnv(prefix + "PASSWORD"),
+			Catalog:    lookupEnv(prefix + "CATALOG"),
+			Schema:     lookupEnv(prefix + "SCHEMA"),
+			Scheme:     lookupEnv(prefix + "SCHEME"),
+			PolicyFile: lookupEnv(prefix + "POLICY_FILE"),
+
+			Auth:              lookupEnv(prefix + "AUTH"),
+			AccessToken:       lookupEnv(prefix + "ACCESS_TOKEN"),
+			AccessTokenFile:   lookupEnv(prefix + "ACCESS_TOKEN_FILE"),
+			KerberosKeytab:    lookupEnv(prefix + "KERBEROS_KEYTAB"),
+			KerberosPrincipal: lookupEnv(prefix + "KERBEROS_PRINCIPAL"),
+			KerberosRealm:     lookupEnv(prefix + "KERBEROS_REALM"),
+			KerberosConfig:    lookupEnv(prefix + "KERBEROS_CONFIG"),
+			KerberosService:   lookupEnv(prefix + "KERBEROS_SERVICE_NAME"),
+			ClientCert:        lookupEnv(prefix + "CLIENT_CERT"),
+			ClientKey:         lookupEnv(prefix + "CLIENT_KEY"),
+			CAFile:            lookupEnv(prefix + "CA_FILE"),
+			TLSServerName:     lookupEnv(prefix + "TLS_SERVER_NAME"),
+			TLSMinVersion:     lookupEnv(prefix + "TLS_MIN_VERSION"),
+		}
+		var err error
+		if s.Port, err = lookupIntEnv(prefix + "PORT"); err != nil {
+			return nil, err
+		}
+		if s.QueryTimeout, err = lookupIntEnv(prefix + "QUERY_TIMEOUT"); err != nil {
+			return nil, err
+		}
+		if s.JobTimeout, err = lookupIntEnv(prefix + "JOB_TIMEOUT"); err != nil {
+			return nil, err
+		}
+		if s.SSL, err = lookupBoolEnv(prefix + "SSL"); err != nil {
+			return nil, err
+		}
+		if s.SSLInsecure, err = lookupBoolEnv(prefix + "SSL_INSECURE"); err != nil {
+			return nil, err
+		}
+		if s.AllowWriteQueries, err = lookupBoolEnv(prefix + "ALLOW_WRITE_QUERIES"); err != nil {
+			return nil, err
+		}
+		settings = append(settings, s)
+	}
+}
+
+// apply returns a copy of base with the cluster's settings applied
+func (s clusterSettings) apply(base *TrinoConfig) (*TrinoConfig, error) {
+	cfg := *base
+	setString(&cfg.Host, s.Host)
+	setString(&cfg.User, s.User)
+	setString(&cfg.Password, s.Password)
+	setString(&cfg.Catalog, s.Catalog)
+	setString(&cfg.Schema, s.Schema)
+	setString(&cfg.Scheme, s.Scheme)
+	setString(&cfg.PolicyFile, s.PolicyFile)
+	setString(&cfg.Auth, s.Auth)
+	setString(&cfg.AccessToken, s.AccessToken)
+	setString(&cfg.AccessTokenFile, s.AccessTokenFile)
+	setString(&cfg.KerberosKeytab, s.KerberosKeytab)
+	setString(&cfg.KerberosPrincipal, s.KerberosPrincipal)
+	setString(&cfg.KerberosRealm, s.KerberosRealm)
+	setString(&cfg.KerberosConfig, s.KerberosConfig)
+	setString(&cfg.KerberosService, s.KerberosService)
+	setString(&cfg.ClientCert, s.ClientCert)
+	setString(&cfg.ClientKey, s.ClientKey)
+	setString(&cfg.CAFile, s.CAFile)
+	setString(&cfg.TLSServerName, s.TLSServerName)
+	setString(&cfg.TLSMinVersion, s.TLSMinVersion)
+	cfg.Auth = strings.ToLower(cfg.Auth)
+	if s.Port != nil {
+		cfg.Port = *s.Port
+	}
+	if s.SSL != nil {
+		cfg.SSL = *s.SSL
+	}
+	if s.SSLInsecure != nil {
+		cfg.SSLInsecure = *s.SSLInsecure
+	}
+	if s.AllowWriteQueries != nil {
+		cfg.AllowWriteQueries = *s.AllowWriteQueries
+	}
+	if s.QueryTimeout != nil {
+		if *s.QueryTimeout <= 0 {
+			return nil, fmt.Errorf("query_timeout must be positive, got %d", *s.QueryTimeout)
+		}
+		cfg.QueryTimeout = time.Duration(*s.QueryTimeout) * time.Second
+	}
+	if s.JobTimeout != nil {
+		if *s.JobTimeout <= 0 {
+			return nil, fmt.Errorf("job_timeout must be positive, got %d", *s.JobTimeout)
+		}
+		cfg.JobTimeout = time.Duration(*s.JobTimeout) * time.Second
+	}
+
+	// If using HTTPS, force SSL to true
+	if strings.EqualFold(cfg.Scheme, "https") {
+		cfg.SSL = true
+	}
+	if cfg.AllowWriteQueries && cfg.PolicyFile == "" && !base.AllowWriteQueries {
+		log.Printf("WARNING: Write queries are enabled for cluster %s. SQL injection protection is bypassed.", s.Name)
+	}
+	return &cfg, nil
+}
+
+func setString(dst *string, value *string) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+func lookupEnv(key string) *string {
+	if value, ok := os.LookupEnv(key); ok {
+		return &value
+	}
+	return nil
+}
+
+func lookupIntEnv(key string) (*int, error) {
+	str := lookupEnv(key)
+	if str == nil {
+		return nil, nil
+	}
+	value, err := strconv.Atoi(*str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: not an integer", key, *str)
+	}
+	return &value, nil
+}
+
+func lookupBoolEnv(key string) (*bool, error) {
+	str := lookupEnv(key)
+	if str == nil {
+		return nil, nil
+	}
+	value, err := strconv.ParseBool(*str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: not a boolean", key, *str)
+	}
+	return &value, nil
+}
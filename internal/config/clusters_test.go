@@ -0,0 +1,137 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClustersConfigFromEnv(t *testing.T) {
+	t.Setenv("TRINO_HOST", "base.example.com")
+	t.Setenv("TRINO_USER", "analyst")
+	t.Setenv("TRINO_CLUSTER_1_NAME", "prod")
+	t.Setenv("TRINO_CLUSTER_1_HOST", "prod.example.com")
+	t.Setenv("TRINO_CLUSTER_2_NAME", "adhoc")
+	t.Setenv("TRINO_CLUSTER_2_PORT", "8443")
+	t.Setenv("TRINO_CLUSTER_2_QUERY_TIMEOUT", "120")
+	t.Setenv("TRINO_CLUSTER_2_JOB_TIMEOUT", "7200")
+	t.Setenv("TRINO_CLUSTER_2_SCHEME", "http")
+	t.Setenv("TRINO_CLUSTER_2_SSL", "false")
+	// Indexes after a gap are ignored
+	t.Setenv("TRINO_CLUSTER_4_NAME", "ignored")
+	t.Setenv("TRINO_DEFAULT_CLUSTER", "adhoc")
+
+	cfg, err := NewClustersConfig()
+	if err != nil {
+		t.Fatalf("NewClustersConfig() error = %v", err)
+	}
+	if cfg.Default != "adhoc" || len(cfg.Clusters) != 2 {
+		t.Fatalf("NewClustersConfig() = default %q, %d clusters; want adhoc, 2", cfg.Default, len(cfg.Clusters))
+	}
+
+	prod, adhoc := cfg.Clusters[0], cfg.Clusters[1]
+	if prod.Name != "prod" || prod.Host != "prod.example.com" || prod.User != "analyst" || !prod.SSL {
+		t.Errorf("prod = %+v, want its own host and inherited user and SSL", prod.TrinoConfig)
+	}
+	if adhoc.Host != "base.example.com" || adhoc.Port != 8443 || adhoc.SSL || adhoc.QueryTimeout != 2*time.Minute ||
+		adhoc.JobTimeout != 2*time.Hour {
+		t.Errorf("adhoc = %+v, want inherited host with its own port, SSL and timeouts", adhoc.TrinoConfig)
+	}
+}
+
+func TestNewClustersConfigFromFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "clusters.yaml")
+	data := `
+default: staging
+clusters:
+  - name: prod
+    host: prod.example.com
+    allow_write_queries: false
+  - name: staging
+    host: staging.example.com
+    catalog: hive
+`
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TRINO_CLUSTERS_FILE", file)
+	// The file takes precedence over indexed variables
+	t.Setenv("TRINO_CLUSTER_1_NAME", "ignored")
+
+	cfg, err := NewClustersConfig()
+	if err != nil {
+		t.Fatalf("NewClustersConfig() error = %v", err)
+	}
+	if cfg.Default != "staging" || len(cfg.Clusters) != 2 || cfg.Clusters[1].Catalog != "hive" {
+		t.Errorf("NewClustersConfig() = %+v", cfg)
+	}
+}
+
+func TestNewClustersConfigSingleCluster(t *testing.T) {
+	t.Setenv("TRINO_HOST", "trino.example.com")
+
+	cfg, err := NewClustersConfig()
+	if err != nil {
+		t.Fatalf("NewClustersConfig() error = %v", err)
+	}
+	if cfg.Default != DefaultClusterName || len(cfg.Clusters) != 1 || cfg.Clusters[0].Host != "trino.example.com" {
+		t.Errorf("NewClustersConfig() = %+v, want one default cluster from TRINO_* settings", cfg)
+	}
+}
+
+func TestNewClustersConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{"duplicate name", map[string]string{"TRINO_CLUSTER_1_NAME": "a", "TRINO_CLUSTER_2_NAME": "a"}},
+		{"invalid name", map[string]string{"TRINO_CLUSTER_1_NAME": "a b"}},
+		{"unknown default", map[string]string{"TRINO_CLUSTER_1_NAME": "a", "TRINO_DEFAULT_CLUSTER": "b"}},
+		{"invalid port", map[string]string{"TRINO_CLUSTER_1_NAME": "a", "TRINO_CLUSTER_1_PORT": "x"}},
+		{"invalid timeout", map[string]string{"TRINO_CLUSTER_1_NAME": "a", "TRINO_CLUSTER_1_QUERY_TIMEOUT": "0"}},
+		{"missing file", map[string]string{"TRINO_CLUSTERS_FILE": "/nonexistent/clusters.yaml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if _, err := NewClustersConfig(); err == nil {
+				t.Error("NewClustersConfig() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestNewClustersConfigValidatesAuth(t *testing.T) {
+	t.Setenv("TRINO_CLUSTER_1_NAME", "prod")
+	t.Setenv("TRINO_CLUSTER_1_AUTH", "JWT")
+	t.Setenv("TRINO_CLUSTER_1_ACCESS_TOKEN", "eyJ.token")
+	t.Setenv("TRINO_CLUSTER_2_NAME", "legacy")
+	t.Setenv("TRINO_CLUSTER_2_AUTH", "kerberos")
+
+	_, err := NewClustersConfig()
+	if err == nil || !strings.Contains(err.Error(), "cluster legacy: kerberos authentication requires a keytab") {
+		t.Errorf("NewClustersConfig() error = %v, want the legacy cluster's missing keytab", err)
+	}
+}
+
+func TestNewClustersConfigInsecureTLS(t *testing.T) {
+	t.Setenv("TRINO_SSL_INSECURE", "true")
+	if _, err := NewClustersConfig(); err != nil {
+		t.Errorf("NewClustersConfig() error = %v, want only a warning outside production", err)
+	}
+
+	t.Setenv("MCP_PROFILE", "production")
+	if _, err := NewClustersConfig(); err == nil || !strings.Contains(err.Error(), "not allowed with MCP_PROFILE=production") {
+		t.Errorf("NewClustersConfig() error = %v, want insecure TLS refused in production", err)
+	}
+
+	// Plain http has no certificate to verify
+	t.Setenv("TRINO_SCHEME", "http")
+	if _, err := NewClustersConfig(); err != nil {
+		t.Errorf("NewClustersConfig() error = %v for an http cluster", err)
+	}
+}
@@ -1,6 +1,10 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"strconv"
@@ -8,6 +12,16 @@ import (
 	"time"
 )
 
+// Authentication methods for connecting to Trino
+const (
+	AuthBasic    = "basic"    // User and optional password
+	AuthJWT      = "jwt"      // Bearer access token
+	AuthKerberos = "kerberos" // SPNEGO with a keytab
+)
+
+// ProfileProduction is the MCP_PROFILE value that refuses insecure settings
+const ProfileProduction = "production"
+
 // TrinoConfig holds Trino connection parameters
 type TrinoConfig struct {
 	Host              string
@@ -19,42 +33,93 @@ type TrinoConfig struct {
 	Scheme            string
 	SSL               bool
 	SSLInsecure       bool
+	Auth              string        // Authentication method: AuthBasic, AuthJWT or AuthKerberos
+	AccessToken       string        // JWT access token for AuthJWT
+	AccessTokenFile   string        // File holding the JWT access token for AuthJWT, re-read when it changes
+	KerberosKeytab    string        // Keytab for AuthKerberos
+	KerberosPrincipal string        // Principal authenticating to the KDC for AuthKerberos
+	KerberosRealm     string        // Kerberos realm for AuthKerberos
+	KerberosConfig    string        // krb5.conf path for AuthKerberos
+	KerberosService   string        // Coordinator's Kerberos service name for AuthKerberos
+	ClientCert        string        // PEM client certificate for mutual TLS
+	ClientKey         string        // PEM private key of ClientCert
+	CAFile            string        // PEM CA bundle verifying the coordinator instead of the system roots
+	TLSServerName     string        // Name verified in the coordinator's certificate instead of Host
+	TLSMinVersion     string        // Minimum TLS version, "1.2" or "1.3"
 	AllowWriteQueries bool          // Controls whether non-read-only SQL queries are allowed
+	PolicyFile        string        // Optional YAML/JSON statement policy; overrides AllowWriteQueries
 	QueryTimeout      time.Duration // Query execution timeout
+	MaxRows           int           // Maximum number of rows returned by execute_query
+	MaxResultBytes    int           // Maximum serialized size of the rows returned by execute_query
+	ResultBufferRows  int           // Maximum rows buffered on the server for pagination
+	ResultBufferBytes int           // Maximum serialized size of one result buffered for pagination
+	ResultStoreBytes  int           // Maximum serialized size of all buffered results together
+	MaxResultHandles  int           // Maximum number of paginated results kept at once
+	ResultTTL         time.Duration // How long an unused paginated result is kept
+	JobWorkers        int           // Number of background jobs submitted with submit_query run at once
+	JobQueueSize      int           // Maximum number of submitted jobs waiting for a worker
+	JobTimeout        time.Duration // Query execution timeout of background jobs
+	JobTTL            time.Duration // How long a finished job and its buffered result are kept
+	// Limits of the EXPLAIN cost preflight run before queries; zero disables a limit
+	MaxEstimatedInputRows  int64 // Estimated rows read from all tables
+	MaxEstimatedInputBytes int64 // Estimated bytes read from all tables
+	MaxEstimatedOutputRows int64 // Estimated rows produced by the query, which catches cross joins
+
+	// Cache of catalog, schema and table listings and table schemas; a zero TTL disables it
+	MetadataCacheTTL  time.Duration // How long a listing is cached
+	MetadataCacheSize int           // Maximum number of cached listings, per cluster
 }
 
 // NewTrinoConfig creates a new TrinoConfig with values from environment variables or defaults
 func NewTrinoConfig() *TrinoConfig {
 	port, _ := strconv.Atoi(getEnv("TRINO_PORT", "8080"))
 	ssl, _ := strconv.ParseBool(getEnv("TRINO_SSL", "true"))
-	sslInsecure, _ := strconv.ParseBool(getEnv("TRINO_SSL_INSECURE", "true"))
+	sslInsecure, _ := strconv.ParseBool(getEnv("TRINO_SSL_INSECURE", "false"))
 	scheme := getEnv("TRINO_SCHEME", "https")
 	allowWriteQueries, _ := strconv.ParseBool(getEnv("TRINO_ALLOW_WRITE_QUERIES", "false"))
 
 	// Parse query timeout from environment variable
 	const defaultTimeout = 30
-	timeoutStr := getEnv("TRINO_QUERY_TIMEOUT", strconv.Itoa(defaultTimeout))
-	timeoutInt, err := strconv.Atoi(timeoutStr)
+	queryTimeout := time.Duration(getPositiveIntEnv("TRINO_QUERY_TIMEOUT", defaultTimeout)) * time.Second
 
-	// Validate timeout value
-	switch {
-	case err != nil:
-		log.Printf("WARNING: Invalid TRINO_QUERY_TIMEOUT '%s': not an integer. Using default of %d seconds", timeoutStr, defaultTimeout)
-		timeoutInt = defaultTimeout
-	case timeoutInt <= 0:
-		log.Printf("WARNING: Invalid TRINO_QUERY_TIMEOUT '%d': must be positive. Using default of %d seconds", timeoutInt, defaultTimeout)
-		timeoutInt = defaultTimeout
-	}
+	// Result guards for execute_query
+	maxRows := getPositiveIntEnv("TRINO_MAX_ROWS", 1000)
+	maxResultBytes := getPositiveIntEnv("TRINO_MAX_RESULT_BYTES", 1024*1024)
 
-	queryTimeout := time.Duration(timeoutInt) * time.Second
+	// Server-side buffers for paginated results
+	resultBufferRows := getPositiveIntEnv("TRINO_RESULT_BUFFER_ROWS", 50000)
+	resultBufferBytes := getPositiveIntEnv("TRINO_RESULT_BUFFER_BYTES", 16*1024*1024)
+	resultStoreBytes := getPositiveIntEnv("TRINO_RESULT_STORE_BYTES", 256*1024*1024)
+	maxResultHandles := getPositiveIntEnv("TRINO_MAX_RESULT_HANDLES", 16)
+	resultTTL := time.Duration(getPositiveIntEnv("TRINO_RESULT_TTL", 600)) * time.Second
+
+	// Background jobs for long-running queries
+	jobWorkers := getPositiveIntEnv("TRINO_JOB_WORKERS", 4)
+	jobQueueSize := getPositiveIntEnv("TRINO_JOB_QUEUE_SIZE", 64)
+	jobTimeout := time.Duration(getPositiveIntEnv("TRINO_JOB_TIMEOUT", 3600)) * time.Second
+	jobTTL := time.Duration(getPositiveIntEnv("TRINO_JOB_TTL", 3600)) * time.Second
+
+	// Cost preflight, disabled unless a limit is set
+	maxInputRows := getLimitEnv("TRINO_MAX_ESTIMATED_INPUT_ROWS")
+	maxInputBytes := getLimitEnv("TRINO_MAX_ESTIMATED_INPUT_BYTES")
+	maxOutputRows := getLimitEnv("TRINO_MAX_ESTIMATED_OUTPUT_ROWS")
+
+	// Metadata cache, disabled with a TTL of zero
+	metadataCacheTTL := time.Duration(getNonNegativeIntEnv("TRINO_METADATA_CACHE_TTL", 300)) * time.Second
+	metadataCacheSize := getPositiveIntEnv("TRINO_METADATA_CACHE_SIZE", 1000)
 
 	// If using HTTPS, force SSL to true
 	if strings.EqualFold(scheme, "https") {
 		ssl = true
 	}
 
+	policyFile := getEnv("TRINO_POLICY_FILE", "")
+
 	// Log a warning if write queries are allowed
-	if allowWriteQueries {
+	switch {
+	case policyFile != "" && allowWriteQueries:
+		log.Printf("WARNING: TRINO_ALLOW_WRITE_QUERIES is ignored because TRINO_POLICY_FILE is set (%s)", policyFile)
+	case allowWriteQueries:
 		log.Println("WARNING: Write queries are enabled (TRINO_ALLOW_WRITE_QUERIES=true). SQL injection protection is bypassed.")
 	}
 
@@ -68,11 +133,164 @@ func NewTrinoConfig() *TrinoConfig {
 		Scheme:            scheme,
 		SSL:               ssl,
 		SSLInsecure:       sslInsecure,
+		Auth:              strings.ToLower(getEnv("TRINO_AUTH", AuthBasic)),
+		AccessToken:       getEnv("TRINO_ACCESS_TOKEN", ""),
+		AccessTokenFile:   getEnv("TRINO_ACCESS_TOKEN_FILE", ""),
+		KerberosKeytab:    getEnv("TRINO_KERBEROS_KEYTAB", ""),
+		KerberosPrincipal: getEnv("TRINO_KERBEROS_PRINCIPAL", ""),
+		KerberosRealm:     getEnv("TRINO_KERBEROS_REALM", ""),
+		KerberosConfig:    getEnv("TRINO_KERBEROS_CONFIG", "/etc/krb5.conf"),
+		KerberosService:   getEnv("TRINO_KERBEROS_SERVICE_NAME", "trino"),
+		ClientCert:        getEnv("TRINO_CLIENT_CERT", ""),
+		ClientKey:         getEnv("TRINO_CLIENT_KEY", ""),
+		CAFile:            getEnv("TRINO_CA_FILE", ""),
+		TLSServerName:     getEnv("TRINO_TLS_SERVER_NAME", ""),
+		TLSMinVersion:     getEnv("TRINO_TLS_MIN_VERSION", "1.2"),
 		AllowWriteQueries: allowWriteQueries,
+		PolicyFile:        policyFile,
 		QueryTimeout:      queryTimeout,
+		MaxRows:           maxRows,
+		MaxResultBytes:    maxResultBytes,
+		ResultBufferRows:  resultBufferRows,
+		ResultBufferBytes: resultBufferBytes,
+		ResultStoreBytes:  resultStoreBytes,
+		MaxResultHandles:  maxResultHandles,
+		ResultTTL:         resultTTL,
+		JobWorkers:        jobWorkers,
+		JobQueueSize:      jobQueueSize,
+		JobTimeout:        jobTimeout,
+		JobTTL:            jobTTL,
+
+		MaxEstimatedInputRows:  maxInputRows,
+		MaxEstimatedInputBytes: maxInputBytes,
+		MaxEstimatedOutputRows: maxOutputRows,
+		MetadataCacheTTL:       metadataCacheTTL,
+		MetadataCacheSize:      metadataCacheSize,
+	}
+}
+
+// Validate checks that the authentication and TLS settings are complete and that the
+// files they name can be loaded
+func (c *TrinoConfig) Validate() error {
+	https := strings.EqualFold(c.Scheme, "https")
+	switch c.Auth {
+	case "", AuthBasic:
+	case AuthJWT:
+		switch {
+		case c.AccessToken != "" && c.AccessTokenFile != "":
+			return errors.New("set either an access token or an access token file, not both")
+		case c.AccessToken == "" && c.AccessTokenFile == "":
+			return errors.New("jwt authentication requires an access token or an access token file")
+		case c.AccessTokenFile != "":
+			token, err := os.ReadFile(c.AccessTokenFile)
+			if err != nil {
+				return fmt.Errorf("failed to read access token file: %w", err)
+			}
+			if len(strings.TrimSpace(string(token))) == 0 {
+				return fmt.Errorf("access token file %s is empty", c.AccessTokenFile)
+			}
+		}
+		if !https {
+			return errors.New("jwt authentication requires the https scheme")
+		}
+	case AuthKerberos:
+		for _, s := range []struct{ name, value string }{
+			{"keytab", c.KerberosKeytab},
+			{"principal", c.KerberosPrincipal},
+			{"realm", c.KerberosRealm},
+			{"config", c.KerberosConfig},
+		} {
+			if s.value == "" {
+				return fmt.Errorf("kerberos authentication requires a %s", s.name)
+			}
+		}
+		for _, file := range []string{c.KerberosKeytab, c.KerberosConfig} {
+			if _, err := os.Stat(file); err != nil {
+				return fmt.Errorf("kerberos authentication: %w", err)
+			}
+		}
+		if !https {
+			return errors.New("kerberos authentication requires the https scheme")
+		}
+	default:
+		return fmt.Errorf("unknown authentication method %q: use %s, %s or %s", c.Auth, AuthBasic, AuthJWT, AuthKerberos)
+	}
+
+	if (c.ClientCert == "") != (c.ClientKey == "") {
+		return errors.New("a client certificate and its key must be set together")
+	}
+	if c.ClientCert != "" {
+		if !https {
+			return errors.New("a client certificate requires the https scheme")
+		}
+		if _, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey); err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+	}
+	if c.CAFile != "" {
+		if !https {
+			return errors.New("a CA file requires the https scheme")
+		}
+		if _, err := LoadCAFile(c.CAFile); err != nil {
+			return err
+		}
+	}
+	if _, err := TLSVersion(c.TLSMinVersion); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkInsecureTLS warns about, or in the production profile refuses, skipping
+// verification of the coordinator's certificate
+func (c *TrinoConfig) checkInsecureTLS(cluster string, production bool) error {
+	if !c.SSLInsecure || !strings.EqualFold(c.Scheme, "https") {
+		return nil
+	}
+	if production {
+		return errors.New("insecure TLS (ssl_insecure) is not allowed with MCP_PROFILE=production")
+	}
+	log.Printf("WARNING: TLS certificate verification is disabled for Trino cluster %s. Use a CA file instead of TRINO_SSL_INSECURE outside of development.", cluster)
+	return nil
+}
+
+// TLSVersion parses a minimum TLS version; empty means TLS 1.2
+func TLSVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported minimum TLS version %q: use 1.2 or 1.3", version)
 	}
 }
 
+// LoadCAFile reads a PEM bundle of CA certificates
+func LoadCAFile(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("CA file %s holds no PEM certificates", file)
+	}
+	return pool, nil
+}
+
+// ShutdownGracePeriod returns how long shutdown waits for running queries before
+// cancelling them, from MCP_SHUTDOWN_GRACE_PERIOD in seconds
+func ShutdownGracePeriod() time.Duration {
+	return time.Duration(getPositiveIntEnv("MCP_SHUTDOWN_GRACE_PERIOD", 30)) * time.Second
+}
+
+// ProgressInterval returns the minimum time between progress notifications of a query,
+// from MCP_PROGRESS_INTERVAL in seconds
+func ProgressInterval() time.Duration {
+	return time.Duration(getPositiveIntEnv("MCP_PROGRESS_INTERVAL", 2)) * time.Second
+}
+
 // getEnv retrieves an environment variable or returns a default value
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -80,3 +298,52 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getPositiveIntEnv parses a positive integer environment variable, logging a warning
+// and returning the default when the value is missing or invalid
+func getPositiveIntEnv(key string, fallback int) int {
+	str := getEnv(key, strconv.Itoa(fallback))
+	value, err := strconv.Atoi(str)
+
+	// Validate value
+	switch {
+	case err != nil:
+		log.Printf("WARNING: Invalid %s '%s': not an integer. Using default of %d", key, str, fallback)
+		return fallback
+	case value <= 0:
+		log.Printf("WARNING: Invalid %s '%d': must be positive. Using default of %d", key, value, fallback)
+		return fallback
+	}
+	return value
+}
+
+// getNonNegativeIntEnv parses a non-negative integer environment variable, logging a
+// warning and returning the default when the value is invalid
+func getNonNegativeIntEnv(key string, fallback int) int {
+	str := getEnv(key, strconv.Itoa(fallback))
+	value, err := strconv.Atoi(str)
+	switch {
+	case err != nil:
+		log.Printf("WARNING: Invalid %s '%s': not an integer. Using default of %d", key, str, fallback)
+		return fallback
+	case value < 0:
+		log.Printf("WARNING: Invalid %s '%d': must not be negative. Using default of %d", key, value, fallback)
+		return fallback
+	}
+	return value
+}
+
+// getLimitEnv parses an optional non-negative limit, where zero or unset means unlimited
+func getLimitEnv(key string) int64 {
+	str := getEnv(key, "0")
+	value, err := strconv.ParseInt(str, 10, 64)
+	switch {
+	case err != nil:
+		log.Printf("WARNING: Invalid %s '%s': not an integer. Ignoring the limit", key, str)
+		return 0
+	case value < 0:
+		log.Printf("WARNING: Invalid %s '%d': must not be negative. Ignoring the limit", key, value)
+		return 0
+	}
+	return value
+}
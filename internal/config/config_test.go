@@ -0,0 +1,101 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeCertificate writes a self-signed certificate and its key and returns their paths
+func writeCertificate(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mcp-trino"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestTrinoConfigValidate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir)
+	tokenFile := filepath.Join(dir, "token")
+	emptyFile := filepath.Join(dir, "empty")
+	for file, data := range map[string]string{tokenFile: "eyJ.token\n", emptyFile: "\n"} {
+		if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	missing := filepath.Join(dir, "missing")
+
+	tests := []struct {
+		name    string
+		cfg     TrinoConfig
+		wantErr string
+	}{
+		{"basic", TrinoConfig{Scheme: "http", Auth: AuthBasic}, ""},
+		{"unset method", TrinoConfig{Scheme: "http"}, ""},
+		{"unknown method", TrinoConfig{Scheme: "https", Auth: "ldap"}, "unknown authentication method"},
+		{"jwt token", TrinoConfig{Scheme: "https", Auth: AuthJWT, AccessToken: "eyJ.token"}, ""},
+		{"jwt token file", TrinoConfig{Scheme: "https", Auth: AuthJWT, AccessTokenFile: tokenFile}, ""},
+		{"jwt without token", TrinoConfig{Scheme: "https", Auth: AuthJWT}, "requires an access token"},
+		{"jwt with both", TrinoConfig{Scheme: "https", Auth: AuthJWT, AccessToken: "t", AccessTokenFile: tokenFile}, "not both"},
+		{"jwt missing file", TrinoConfig{Scheme: "https", Auth: AuthJWT, AccessTokenFile: missing}, "failed to read access token file"},
+		{"jwt empty file", TrinoConfig{Scheme: "https", Auth: AuthJWT, AccessTokenFile: emptyFile}, "is empty"},
+		{"jwt over http", TrinoConfig{Scheme: "http", Auth: AuthJWT, AccessToken: "t"}, "requires the https scheme"},
+		{"kerberos", TrinoConfig{Scheme: "https", Auth: AuthKerberos, KerberosKeytab: tokenFile, KerberosPrincipal: "svc", KerberosRealm: "EXAMPLE.COM", KerberosConfig: tokenFile}, ""},
+		{"kerberos without realm", TrinoConfig{Scheme: "https", Auth: AuthKerberos, KerberosKeytab: tokenFile, KerberosPrincipal: "svc", KerberosConfig: tokenFile}, "requires a realm"},
+		{"kerberos missing keytab", TrinoConfig{Scheme: "https", Auth: AuthKerberos, KerberosKeytab: missing, KerberosPrincipal: "svc", KerberosRealm: "EXAMPLE.COM", KerberosConfig: tokenFile}, "no such file"},
+		{"client certificate", TrinoConfig{Scheme: "https", ClientCert: certFile, ClientKey: keyFile, CAFile: certFile}, ""},
+		{"certificate without key", TrinoConfig{Scheme: "https", ClientCert: certFile}, "must be set together"},
+		{"mismatched key", TrinoConfig{Scheme: "https", ClientCert: keyFile, ClientKey: certFile}, "failed to load client certificate"},
+		{"CA file without certificates", TrinoConfig{Scheme: "https", CAFile: tokenFile}, "holds no PEM certificates"},
+		{"CA file over http", TrinoConfig{Scheme: "http", CAFile: certFile}, "requires the https scheme"},
+		{"TLS 1.3", TrinoConfig{Scheme: "https", TLSMinVersion: "1.3"}, ""},
+		{"TLS 1.0", TrinoConfig{Scheme: "https", TLSMinVersion: "1.0"}, "unsupported minimum TLS version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Package cors restricts cross-origin browser access to the HTTP transport.
+package cors
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	allowedMethods = "GET, POST, DELETE, OPTIONS"
+	allowedHeaders = "Content-Type, Accept, Authorization, X-API-Key, Mcp-Session-Id, Mcp-Protocol-Version, Last-Event-ID"
+	// exposedHeaders lets browser clients read the streamable HTTP session ID
+	exposedHeaders = "Mcp-Session-Id"
+)
+
+// Policy is a list of origins allowed to call the server from a browser
+type Policy struct {
+	origins map[string]bool
+	any     bool
+}
+
+// NewPolicy returns a policy allowing the given origins, e.g. "https://app.example.com".
+// "*" allows every origin; an empty list allows none.
+func NewPolicy(origins []string) *Policy {
+	p := &Policy{origins: make(map[string]bool, len(origins))}
+	for _, origin := range origins {
+		origin = strings.TrimSpace(origin)
+		switch origin {
+		case "":
+		case "*":
+			p.any = true
+		default:
+			p.origins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+		}
+	}
+	return p
+}
+
+// ParseOrigins splits a comma-separated origin list
+func ParseOrigins(s string) []string {
+	var origins []string
+	for _, origin := range strings.Split(s, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// Allowed reports whether a browser on origin may call the server
+func (p *Policy) Allowed(origin string) bool {
+	return p.any || p.origins[strings.ToLower(origin)]
+}
+
+// Handler answers preflight requests and adds CORS headers for allowed origins.
+// Requests from other origins are refused, since browsers send some cross-origin
+// POSTs without a preflight. Requests without an Origin header, i.e. from
+// non-browser clients, are passed through unchanged.
+func (p *Policy) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		if !p.Allowed(origin) {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Expose-Headers", exposedHeaders)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
+			w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,61 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPolicyHandler(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		origins    []string
+		method     string
+		origin     string
+		preflight  bool
+		wantCode   int
+		wantOrigin string
+	}{
+		{"no origin", nil, http.MethodPost, "", false, http.StatusOK, ""},
+		{"allowed origin", []string{"https://app.example.com/"}, http.MethodPost, "https://app.example.com", false, http.StatusOK, "https://app.example.com"},
+		{"origin case", []string{"https://App.Example.com"}, http.MethodGet, "https://app.example.com", false, http.StatusOK, "https://app.example.com"},
+		{"disallowed origin", []string{"https://app.example.com"}, http.MethodPost, "https://evil.example.com", false, http.StatusForbidden, ""},
+		{"empty allow-list", nil, http.MethodPost, "https://app.example.com", false, http.StatusForbidden, ""},
+		{"wildcard", []string{"*"}, http.MethodPost, "https://any.example.com", false, http.StatusOK, "https://any.example.com"},
+		{"preflight", []string{"https://app.example.com"}, http.MethodOptions, "https://app.example.com", true, http.StatusNoContent, "https://app.example.com"},
+		{"disallowed preflight", []string{"https://app.example.com"}, http.MethodOptions, "https://evil.example.com", true, http.StatusForbidden, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/query", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			rec := httptest.NewRecorder()
+			NewPolicy(tt.origins).Handler(next).ServeHTTP(rec, req)
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if tt.preflight && tt.wantCode == http.StatusNoContent && rec.Header().Get("Access-Control-Allow-Headers") == "" {
+				t.Error("preflight response lacks Access-Control-Allow-Headers")
+			}
+		})
+	}
+}
+
+func TestParseOrigins(t *testing.T) {
+	got := ParseOrigins(" https://a.example.com, ,https://b.example.com ")
+	if len(got) != 2 || got[0] != "https://a.example.com" || got[1] != "https://b.example.com" {
+		t.Errorf("ParseOrigins() = %q", got)
+	}
+}
@@ -0,0 +1,160 @@
+package format
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// jsonEncoder writes the whole result as indented JSON, rows as positional arrays
+type jsonEncoder struct{}
+
+func (jsonEncoder) ContentType() string { return "application/json" }
+
+func (jsonEncoder) Encode(w io.Writer, result *trino.QueryResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+// columnsEncoder writes compact column-oriented JSON: values[j] holds every value of column j
+type columnsEncoder struct{}
+
+func (columnsEncoder) ContentType() string { return "application/json" }
+
+func (columnsEncoder) Encode(w io.Writer, result *trino.QueryResult) error {
+	values := make([][]interface{}, len(result.Columns))
+	for j := range values {
+		values[j] = make([]interface{}, len(result.Rows))
+		for i, row := range result.Rows {
+			values[j][i] = row[j]
+		}
+	}
+	return json.NewEncoder(w).Encode(struct {
+		Columns []trino.Column  `json:"columns"`
+		Values  [][]interface{} `json:"values"`
+	}{result.Columns, values})
+}
+
+// markdownEncoder writes a GitHub-flavored markdown table
+type markdownEncoder struct{}
+
+func (markdownEncoder) ContentType() string { return "text/markdown; charset=utf-8" }
+
+func (markdownEncoder) Encode(w io.Writer, result *trino.QueryResult) error {
+	bw := bufio.NewWriter(w)
+	writeLine := func(cells []string) {
+		bw.WriteString("|")
+		for _, cell := range cells {
+			bw.WriteString(" " + cell + " |")
+		}
+		bw.WriteString("\n")
+	}
+
+	header := make([]string, len(result.Columns))
+	separator := make([]string, len(result.Columns))
+	for i, name := range columnNames(result) {
+		header[i] = markdownEscape(name)
+		separator[i] = "---"
+	}
+	writeLine(header)
+	writeLine(separator)
+
+	cells := make([]string, len(result.Columns))
+	for _, row := range result.Rows {
+		for i, v := range row {
+			cells[i] = markdownEscape(cellText(v, "NULL"))
+		}
+		writeLine(cells)
+	}
+	return bw.Flush()
+}
+
+var markdownReplacer = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+func markdownEscape(s string) string {
+	return markdownReplacer.Replace(s)
+}
+
+// csvEncoder writes RFC 4180 CSV with a header row; NULL is an empty field
+type csvEncoder struct{}
+
+func (csvEncoder) ContentType() string { return "text/csv; charset=utf-8" }
+
+func (csvEncoder) Encode(w io.Writer, result *trino.QueryResult) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columnNames(result)); err != nil {
+		return err
+	}
+	record := make([]string, len(result.Columns))
+	for _, row := range result.Rows {
+		for i, v := range row {
+			record[i] = cellText(v, "")
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// tsvEncoder writes tab-separated values with a header row. Tabs, newlines and
+// backslashes in values are escaped as \t, \n, \r and \\; NULL is written as \N.
+type tsvEncoder struct{}
+
+func (tsvEncoder) ContentType() string { return "text/tab-separated-values; charset=utf-8" }
+
+func (tsvEncoder) Encode(w io.Writer, result *trino.QueryResult) error {
+	bw := bufio.NewWriter(w)
+	writeLine := func(cells []string) {
+		bw.WriteString(strings.Join(cells, "\t"))
+		bw.WriteString("\n")
+	}
+
+	header := columnNames(result)
+	for i, name := range header {
+		header[i] = tsvEscape(name)
+	}
+	writeLine(header)
+
+	cells := make([]string, len(result.Columns))
+	for _, row := range result.Rows {
+		for i, v := range row {
+			if v == nil {
+				cells[i] = `\N`
+				continue
+			}
+			cells[i] = tsvEscape(cellText(v, ""))
+		}
+		writeLine(cells)
+	}
+	return bw.Flush()
+}
+
+var tsvReplacer = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func tsvEscape(s string) string {
+	return tsvReplacer.Replace(s)
+}
+
+// jsonlEncoder writes one JSON object per row with keys in column order. Duplicate
+// column names are numbered so no value is lost to a repeated key.
+type jsonlEncoder struct{}
+
+func (jsonlEncoder) ContentType() string { return "application/jsonl" }
+
+func (jsonlEncoder) Encode(w io.Writer, result *trino.QueryResult) error {
+	enc := json.NewEncoder(w)
+	names := uniqueColumnNames(result)
+	for _, row := range result.Rows {
+		if err := enc.Encode(trino.RowValue{Fields: names, Values: row}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
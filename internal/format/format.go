@@ -0,0 +1,130 @@
+// Package format renders query results in the output formats offered to clients.
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// Built-in format names
+const (
+	JSON     = "json"
+	Columns  = "columns"
+	Markdown = "markdown"
+	CSV      = "csv"
+	TSV      = "tsv"
+	JSONL    = "jsonl"
+)
+
+// Encoder renders a query result
+type Encoder interface {
+	// Encode writes the result to w
+	Encode(w io.Writer, result *trino.QueryResult) error
+	// ContentType is the MIME type of the encoded output
+	ContentType() string
+}
+
+var (
+	mu       sync.RWMutex
+	encoders = make(map[string]Encoder)
+)
+
+func init() {
+	Register(JSON, jsonEncoder{})
+	Register(Columns, columnsEncoder{})
+	Register(Markdown, markdownEncoder{})
+	Register(CSV, csvEncoder{})
+	Register(TSV, tsvEncoder{})
+	Register(JSONL, jsonlEncoder{})
+}
+
+// Register makes an encoder available under a name, replacing any encoder with that name
+func Register(name string, enc Encoder) {
+	mu.Lock()
+	defer mu.Unlock()
+	encoders[strings.ToLower(name)] = enc
+}
+
+// Get returns the encoder registered under a name (case-insensitive)
+func Get(name string) (Encoder, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	enc, ok := encoders[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (supported: %s)", name, strings.Join(namesLocked(), ", "))
+	}
+	return enc, nil
+}
+
+// Names returns the registered format names in sorted order
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return namesLocked()
+}
+
+func namesLocked() []string {
+	names := make([]string, 0, len(encoders))
+	for name := range encoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cellText renders a value for text formats: strings as-is, NULL as nullText
+// and everything else (numbers, nested values, binaries) as JSON
+func cellText(v interface{}, nullText string) string {
+	switch x := v.(type) {
+	case nil:
+		return nullText
+	case string:
+		return x
+	case json.RawMessage:
+		return string(x)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// columnNames returns the names of the result columns in order
+func columnNames(result *trino.QueryResult) []string {
+	names := make([]string, len(result.Columns))
+	for i, col := range result.Columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// uniqueColumnNames returns the column names with duplicates numbered, e.g. id and id_2
+// for SELECT a.id, b.id, so they can be used as object keys
+func uniqueColumnNames(result *trino.QueryResult) []string {
+	names := columnNames(result)
+	taken := make(map[string]bool, len(names))
+	for _, name := range names {
+		taken[name] = true
+	}
+	seen := make(map[string]bool, len(names))
+	for i, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			continue
+		}
+		unique := name
+		for n := 2; taken[unique]; n++ {
+			unique = fmt.Sprintf("%s_%d", name, n)
+		}
+		names[i] = unique
+		taken[unique] = true
+	}
+	return names
+}
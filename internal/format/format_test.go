@@ -0,0 +1,109 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+func testResult() *trino.QueryResult {
+	return &trino.QueryResult{
+		Columns: []trino.Column{
+			{Name: "id", Type: "bigint"},
+			{Name: "name", Type: "varchar"},
+			{Name: "tags", Type: "array(varchar)"},
+		},
+		Rows: [][]interface{}{
+			{int64(1), "a|b", []interface{}{"x"}},
+			{int64(2), "line\none\tcol", nil},
+		},
+		RowCount: 2,
+	}
+}
+
+func TestEncoders(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{Markdown, "| id | name | tags |\n| --- | --- | --- |\n" +
+			"| 1 | a\\|b | [\"x\"] |\n" +
+			"| 2 | line<br>one\tcol | NULL |\n"},
+		{CSV, "id,name,tags\n" +
+			"1,a|b,\"[\"\"x\"\"]\"\n" +
+			"2,\"line\none\tcol\",\n"},
+		{TSV, "id\tname\ttags\n" +
+			"1\ta|b\t[\"x\"]\n" +
+			"2\tline\\none\\tcol\t\\N\n"},
+		{JSONL, `{"id":1,"name":"a|b","tags":["x"]}` + "\n" +
+			`{"id":2,"name":"line\none\tcol","tags":null}` + "\n"},
+		{Columns, `{"columns":[{"name":"id","type":"bigint"},{"name":"name","type":"varchar"},` +
+			`{"name":"tags","type":"array(varchar)"}],"values":[[1,2],["a|b","line\none\tcol"],[["x"],null]]}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			enc, err := Get(tt.format)
+			if err != nil {
+				t.Fatalf("Get(%q) error = %v", tt.format, err)
+			}
+			var buf bytes.Buffer
+			if err := enc.Encode(&buf, testResult()); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Encode() =\n%q\nwant\n%q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONEncoderRoundTrips(t *testing.T) {
+	enc, err := Get("JSON")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := enc.Encode(&buf, testResult()); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	var decoded trino.QueryResult
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if len(decoded.Columns) != 3 || decoded.RowCount != 2 {
+		t.Errorf("decoded %d columns and %d rows, want 3 and 2", len(decoded.Columns), decoded.RowCount)
+	}
+}
+
+func TestJSONLDuplicateColumns(t *testing.T) {
+	result := &trino.QueryResult{
+		Columns: []trino.Column{
+			{Name: "id", Type: "bigint"},
+			{Name: "id", Type: "bigint"},
+			{Name: "id_2", Type: "bigint"},
+			{Name: "id", Type: "bigint"},
+		},
+		Rows: [][]interface{}{{int64(1), int64(2), int64(3), int64(4)}},
+	}
+	enc, err := Get(JSONL)
+	if err != nil {
+		t.Fatalf("Get(%q) error = %v", JSONL, err)
+	}
+	var buf bytes.Buffer
+	if err := enc.Encode(&buf, result); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	want := `{"id":1,"id_3":2,"id_2":3,"id_4":4}` + "\n"
+	if buf.String() != want {
+		t.Errorf("Encode() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestGetUnknownFormat(t *testing.T) {
+	if _, err := Get("xml"); err == nil {
+		t.Error("Get(\"xml\") succeeded, want error")
+	}
+}
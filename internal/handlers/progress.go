@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// progressReporter sends the progress of a tool call's queries to the client as MCP
+// progress notifications for the request's progress token. It reports at most once
+// per interval, and whenever the query state changes.
+type progressReporter struct {
+	ctx      context.Context
+	server   *server.MCPServer
+	token    mcp.ProgressToken
+	interval time.Duration
+
+	mu       sync.Mutex
+	sent     time.Time
+	state    string
+	progress float64
+	stopped  bool
+}
+
+// withProgress returns a context whose queries report their progress to the client, if
+// the request asked for progress notifications. The returned function ends reporting;
+// call it before returning the tool result, as no notification may follow the result.
+func (h *TrinoHandlers) withProgress(ctx context.Context, request mcp.CallToolRequest) (context.Context, func()) {
+	if h.ProgressInterval <= 0 || request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return ctx, func() {}
+	}
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return ctx, func() {}
+	}
+	r := &progressReporter{
+		ctx:      ctx,
+		server:   mcpServer,
+		token:    request.Params.Meta.ProgressToken,
+		interval: h.ProgressInterval,
+	}
+	return trino.WithProgress(ctx, r.report), r.stop
+}
+
+func (r *progressReporter) stop() {
+	r.mu.Lock()
+	r.stopped = true
+	r.mu.Unlock()
+}
+
+func (r *progressReporter) report(info trino.QueryInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if r.stopped || (info.State == r.state && now.Sub(r.sent) < r.interval) {
+		return
+	}
+	r.sent, r.state = now, info.State
+
+	// Progress counts completed splits and never goes backwards, as MCP requires
+	params := map[string]any{
+		"progressToken": r.token,
+		"message":       progressMessage(info),
+	}
+	if float64(info.CompletedSplits) > r.progress {
+		r.progress = float64(info.CompletedSplits)
+	}
+	params["progress"] = r.progress
+	if info.TotalSplits > 0 && float64(info.TotalSplits) >= r.progress {
+		params["total"] = info.TotalSplits
+	}
+
+	if err := r.server.SendNotificationToClient(r.ctx, "notifications/progress", params); err != nil {
+		// The client is gone or not reading; stop reporting rather than log every update
+		log.Printf("Stopped progress notifications: %v", err)
+		r.stopped = true
+	}
+}
+
+// progressMessage summarizes a query's progress, e.g.
+// "RUNNING: 120/400 splits, 1500000 rows, 1.2 GB processed, 12s elapsed"
+func progressMessage(info trino.QueryInfo) string {
+	state := info.State
+	if state == "" {
+		state = "STARTING"
+	}
+	return fmt.Sprintf("%s: %d/%d splits, %d rows, %s processed, %s elapsed",
+		state, info.CompletedSplits, info.TotalSplits, info.ProcessedRows,
+		formatBytes(info.ProcessedBytes), (time.Duration(info.ElapsedMillis) * time.Millisecond).Round(time.Second))
+}
+
+// formatBytes renders a byte count with a decimal unit
+func formatBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, exp := float64(n)/unit, 0
+	for value >= unit && exp < 4 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", value, "kMGTP"[exp])
+}
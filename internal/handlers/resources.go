@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+)
+
+// Resource URIs. Names are percent-encoded path segments and are taken literally, so
+// they are quoted before they are passed to the client. The catalog listing has an
+// empty authority so it cannot be mistaken for a catalog, whatever its name. The URIs
+// name no cluster, so resources are always read from the default cluster.
+const (
+	CatalogsURI        = "trino:///catalogs"
+	CatalogURITemplate = "trino://{catalog}"
+	SchemaURITemplate  = "trino://{catalog}/{schema}"
+	TableURITemplate   = "trino://{catalog}/{schema}/{table}"
+)
+
+// resourceEntry links a child object to its resource
+type resourceEntry struct {
+	Name string `json:"name"`
+	URI  string `json:"uri"`
+}
+
+// ReadCatalogs lists catalogs with links to their resources
+func (h *TrinoHandlers) ReadCatalogs(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	catalogs, err := h.Clusters.Default().ListCatalogs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list catalogs: %w", err)
+	}
+	return jsonResource(request.Params.URI, resourceEntries(catalogs))
+}
+
+// ReadCatalog lists the schemas of a catalog with links to their resources
+func (h *TrinoHandlers) ReadCatalog(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	catalog, err := resourceArgument(request, "catalog")
+	if err != nil {
+		return nil, err
+	}
+	schemas, err := h.Clusters.Default().ListSchemas(ctx, trino.QuoteIdentifier(catalog))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schemas: %w", err)
+	}
+	return jsonResource(request.Params.URI, resourceEntries(schemas, catalog))
+}
+
+// ReadSchema lists the tables of a schema with links to their resources
+func (h *TrinoHandlers) ReadSchema(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	catalog, err := resourceArgument(request, "catalog")
+	if err != nil {
+		return nil, err
+	}
+	schema, err := resourceArgument(request, "schema")
+	if err != nil {
+		return nil, err
+	}
+	tables, err := h.Clusters.Default().ListTables(ctx, trino.QuoteIdentifier(catalog), trino.QuoteIdentifier(schema))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	return jsonResource(request.Params.URI, resourceEntries(tables, catalog, schema))
+}
+
+// ReadTable describes a table: its columns, comment and CREATE statement
+func (h *TrinoHandlers) ReadTable(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	catalog, err := resourceArgument(request, "catalog")
+	if err != nil {
+		return nil, err
+	}
+	schema, err := resourceArgument(request, "schema")
+	if err != nil {
+		return nil, err
+	}
+	table, err := resourceArgument(request, "table")
+	if err != nil {
+		return nil, err
+	}
+
+	// The comment and DDL are best effort: access to them may be restricted
+	details, err := h.Clusters.Default().DescribeTable(ctx, "", "", trino.QuoteQualifiedName(catalog, schema, table),
+		[]string{trino.IncludeComment, trino.IncludeDDL})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table schema: %w", err)
+	}
+	for _, warning := range details.Warnings {
+		log.Printf("Error describing %s.%s.%s: %s", catalog, schema, table, warning)
+	}
+
+	return jsonResource(request.Params.URI, details)
+}
+
+// resourceArgument returns a non-empty variable matched from the resource URI template.
+// The server passes matched variables as a list of decoded values.
+func resourceArgument(request mcp.ReadResourceRequest, name string) (string, error) {
+	var value string
+	switch v := request.Params.Arguments[name].(type) {
+	case string:
+		value = v
+	case []string:
+		if len(v) == 1 {
+			value = v[0]
+		}
+	}
+	if value == "" {
+		return "", fmt.Errorf("resource URI is missing the %s name", name)
+	}
+	return value, nil
+}
+
+// resourceEntries links each name to its resource below the given parent path
+func resourceEntries(names []string, parents ...string) []resourceEntry {
+	prefix := "trino://"
+	for _, parent := range parents {
+		prefix += EscapeResourceName(parent) + "/"
+	}
+	entries := make([]resourceEntry, len(names))
+	for i, name := range names {
+		entries[i] = resourceEntry{Name: name, URI: prefix + EscapeResourceName(name)}
+	}
+	return entries
+}
+
+// EscapeResourceName percent-encodes a name for use as a resource URI segment.
+// Only unreserved characters are kept, as required by the URI templates.
+func EscapeResourceName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-._~", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func jsonResource(uri string, v interface{}) ([]mcp.ResourceContents, error) {
+	jsonData, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resource to JSON: %w", err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(jsonData)},
+	}, nil
+}
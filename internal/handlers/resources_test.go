@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+func TestEscapeResourceName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"orders", "orders"},
+		{"a-b_c.d~e", "a-b_c.d~e"},
+		{"c/d", "c%2Fd"},
+		{`e"f`, "e%22f"},
+		{"with space", "with%20space"},
+		{"100%", "100%25"},
+		{"café", "caf%C3%A9"},
+	}
+
+	for _, tt := range tests {
+		if got := EscapeResourceName(tt.name); got != tt.want {
+			t.Errorf("EscapeResourceName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// newResourceClient serves the resources of a fake coordinator to an in-process MCP client
+func newResourceClient(t *testing.T, coordinator *trinotest.Server) *client.Client {
+	t.Helper()
+	clusters, err := trino.NewClusters(&config.ClustersConfig{
+		Default:  "default",
+		Clusters: []config.ClusterConfig{{Name: "default", TrinoConfig: coordinator.Config()}},
+	})
+	if err != nil {
+		t.Fatalf("NewClusters() error = %v", err)
+	}
+	t.Cleanup(func() { _ = clusters.Close() })
+
+	h := &TrinoHandlers{Clusters: clusters}
+	mcpServer := server.NewMCPServer("test", "1.0", server.WithResourceCapabilities(false, false))
+	mcpServer.AddResource(mcp.NewResource(CatalogsURI, "Trino catalogs"), h.ReadCatalogs)
+	mcpServer.AddResourceTemplate(mcp.NewResourceTemplate(CatalogURITemplate, "Trino catalog"), h.ReadCatalog)
+	mcpServer.AddResourceTemplate(mcp.NewResourceTemplate(SchemaURITemplate, "Trino schema"), h.ReadSchema)
+	mcpServer.AddResourceTemplate(mcp.NewResourceTemplate(TableURITemplate, "Trino table"), h.ReadTable)
+
+	c, err := client.NewInProcessClient(mcpServer)
+	if err != nil {
+		t.Fatalf("NewInProcessClient() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "mcp-trino-test", Version: "1.0"}
+	if _, err := c.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	return c
+}
+
+// readEntries reads a listing resource
+func readEntries(t *testing.T, c *client.Client, uri string) []resourceEntry {
+	t.Helper()
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = uri
+	result, err := c.ReadResource(context.Background(), request)
+	if err != nil {
+		t.Fatalf("ReadResource(%s) error = %v", uri, err)
+	}
+	var entries []resourceEntry
+	if err := json.Unmarshal([]byte(result.Contents[0].(mcp.TextResourceContents).Text), &entries); err != nil {
+		t.Fatalf("ReadResource(%s) returned invalid JSON: %v", uri, err)
+	}
+	return entries
+}
+
+func TestResourceNames(t *testing.T) {
+	coordinator := trinotest.NewServer()
+	defer coordinator.Close()
+	listing := func(column string, names ...string) trinotest.Result {
+		result := trinotest.Result{Columns: []trinotest.Column{{Name: column, Type: "varchar"}}}
+		for _, name := range names {
+			result.Rows = append(result.Rows, []interface{}{name})
+		}
+		return result
+	}
+	coordinator.SetResult("SHOW CATALOGS", listing("Catalog", "a.b", "catalogs"))
+	coordinator.SetResult(`SHOW SCHEMAS FROM "a.b"`, listing("Schema", "c/d"))
+	coordinator.SetResult(`SHOW SCHEMAS FROM "catalogs"`, listing("Schema", "default"))
+	coordinator.SetResult(`SHOW TABLES FROM "a.b"."c/d"`, listing("Table", `e"f`))
+	coordinator.SetResult(`DESCRIBE "a.b"."c/d"."e""f"`, trinotest.Result{
+		Columns: []trinotest.Column{
+			{Name: "Column", Type: "varchar"}, {Name: "Type", Type: "varchar"},
+			{Name: "Extra", Type: "varchar"}, {Name: "Comment", Type: "varchar"},
+		},
+		Rows: [][]interface{}{{"id", "bigint", "", ""}},
+	})
+	c := newResourceClient(t, coordinator)
+
+	// Each listing links to the next level; following the links must reach the same names
+	catalogs := readEntries(t, c, CatalogsURI)
+	if len(catalogs) != 2 || catalogs[0].URI != "trino://a.b" || catalogs[1].URI != "trino://catalogs" {
+		t.Fatalf("catalogs = %+v, want links to trino://a.b and trino://catalogs", catalogs)
+	}
+
+	// A catalog named catalogs is not shadowed by the catalog listing
+	if schemas := readEntries(t, c, catalogs[1].URI); len(schemas) != 1 || schemas[0].Name != "default" {
+		t.Errorf("schemas of catalogs = %+v, want default", schemas)
+	}
+
+	schemas := readEntries(t, c, catalogs[0].URI)
+	if len(schemas) != 1 || schemas[0].Name != "c/d" || schemas[0].URI != "trino://a.b/c%2Fd" {
+		t.Fatalf("schemas = %+v, want c/d at trino://a.b/c%%2Fd", schemas)
+	}
+	tables := readEntries(t, c, schemas[0].URI)
+	if len(tables) != 1 || tables[0].Name != `e"f` || tables[0].URI != "trino://a.b/c%2Fd/e%22f" {
+		t.Fatalf("tables = %+v, want e\"f at trino://a.b/c%%2Fd/e%%22f", tables)
+	}
+
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = tables[0].URI
+	result, err := c.ReadResource(context.Background(), request)
+	if err != nil {
+		t.Fatalf("ReadResource(%s) error = %v", tables[0].URI, err)
+	}
+	var details trino.TableDetails
+	if err := json.Unmarshal([]byte(result.Contents[0].(mcp.TextResourceContents).Text), &details); err != nil {
+		t.Fatalf("ReadResource(%s) returned invalid JSON: %v", tables[0].URI, err)
+	}
+	if len(details.Columns) != 1 || details.Columns[0].Name != "id" {
+		t.Errorf("table columns = %+v, want id", details.Columns)
+	}
+}
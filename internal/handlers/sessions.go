@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tuannvm/mcp-trino/internal/auth"
+)
+
+// SessionContexts ties tool calls to the lifetime of the client session that issued them.
+//
+// The SSE transport answers each message POST with 202 Accepted and runs the tool in the
+// background, detached from the POST's context. Instead, tool calls are bound to the
+// context of the session's event stream, which is cancelled when the client disconnects;
+// that cancellation propagates to the running Trino queries. Streamable HTTP tool calls
+// run within their POST and are cancelled with it as well.
+type SessionContexts struct {
+	mu       sync.Mutex
+	sessions map[string]context.Context
+}
+
+// NewSessionContexts creates an empty session registry
+func NewSessionContexts() *SessionContexts {
+	return &SessionContexts{sessions: make(map[string]context.Context)}
+}
+
+// Register installs hooks that track session lifetimes
+func (s *SessionContexts) Register(hooks *server.Hooks) {
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		s.mu.Lock()
+		s.sessions[session.SessionID()] = ctx
+		s.mu.Unlock()
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		s.mu.Lock()
+		delete(s.sessions, session.SessionID())
+		s.mu.Unlock()
+	})
+}
+
+// ToolMiddleware is a server.ToolHandlerMiddleware that cancels a tool call when its
+// session ends
+func (s *SessionContexts) ToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		session := server.ClientSessionFromContext(ctx)
+		if session == nil {
+			return next(ctx, request)
+		}
+
+		s.mu.Lock()
+		sessionCtx, ok := s.sessions[session.SessionID()]
+		s.mu.Unlock()
+		// Streamable HTTP registers a session with the context of its initialize POST,
+		// which has already ended; only a live session context is worth binding to
+		if !ok || sessionCtx.Err() != nil {
+			return next(ctx, request)
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(sessionCtx, cancel)
+		defer stop()
+		return next(ctx, request)
+	}
+}
+
+// RequireOwner rejects messages posted to a session by a caller other than the one
+// that opened it, so an authenticated caller cannot drive another caller's session.
+// SSE names the session in the sessionId parameter, streamable HTTP in a header.
+func (s *SessionContexts) RequireOwner(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if caller, ok := auth.IdentityFromContext(r.Context()); ok {
+			sessionID := r.URL.Query().Get("sessionId")
+			if sessionID == "" {
+				sessionID = r.Header.Get(server.HeaderKeySessionID)
+			}
+			s.mu.Lock()
+			sessionCtx, found := s.sessions[sessionID]
+			s.mu.Unlock()
+			if found {
+				if owner, _ := auth.IdentityFromContext(sessionCtx); owner.Principal != caller.Principal {
+					http.Error(w, "session belongs to another user", http.StatusForbidden)
+					return
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tuannvm/mcp-trino/internal/trino"
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+func TestSSEDisconnectCancelsQuery(t *testing.T) {
+	coordinator := trinotest.NewServer()
+	defer coordinator.Close()
+	coordinator.SetResult("SELECT * FROM huge", trinotest.Result{Block: true})
+	trinoClient, err := trino.NewClient(coordinator.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer trinoClient.Close()
+
+	sessions := NewSessionContexts()
+	hooks := &server.Hooks{}
+	sessions.Register(hooks)
+	mcpServer := server.NewMCPServer("test", "1.0",
+		server.WithHooks(hooks),
+		server.WithToolHandlerMiddleware(sessions.ToolMiddleware),
+	)
+	mcpServer.AddTool(mcp.NewTool("slow_query"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if _, err := trinoClient.ExecuteQuery(ctx, "SELECT * FROM huge"); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+	srv := server.NewTestServer(mcpServer)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	c, err := client.NewSSEMCPClient(srv.URL + "/sse")
+	if err != nil {
+		t.Fatalf("NewSSEMCPClient() error = %v", err)
+	}
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "mcp-trino-test", Version: "1.0"}
+	if _, err := c.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	go func() {
+		callRequest := mcp.CallToolRequest{}
+		callRequest.Params.Name = "slow_query"
+		_, _ = c.CallTool(ctx, callRequest)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(coordinator.Requests()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the tool call did not reach the coordinator")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Closing the client ends its event stream, which cancels the query on the coordinator
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	for len(coordinator.Cancelled()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the query was not cancelled after the client disconnected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
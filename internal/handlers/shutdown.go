@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ToolCalls tracks in-flight tool calls so that shutdown can refuse new calls and let
+// the running ones deliver their results
+type ToolCalls struct {
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// Middleware is a server.ToolHandlerMiddleware that refuses calls once Close was called
+func (t *ToolCalls) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		t.mu.Lock()
+		if t.closed {
+			t.mu.Unlock()
+			return mcp.NewToolResultError("server is shutting down"), nil
+		}
+		t.wg.Add(1)
+		t.mu.Unlock()
+		defer t.wg.Done()
+		return next(ctx, request)
+	}
+}
+
+// Close refuses further tool calls
+func (t *ToolCalls) Close() {
+	t.mu.Lock()
+	t.closed = true
+	t.mu.Unlock()
+}
+
+// Wait waits until the running tool calls have returned or ctx is done
+func (t *ToolCalls) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestToolCalls(t *testing.T) {
+	calls := &ToolCalls{}
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := calls.Middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-release
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	results := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		result, _ := handler(context.Background(), mcp.CallToolRequest{})
+		results <- result
+	}()
+	<-started
+	calls.Close()
+
+	// Calls after Close are refused without reaching the handler
+	refused, err := handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil || !refused.IsError {
+		t.Errorf("call after Close = %+v, %v; want a tool error", refused, err)
+	}
+
+	// Wait honours its ctx while a call is still running
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := calls.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() with a call in flight error = %v, want context.DeadlineExceeded", err)
+	}
+
+	// Wait returns once the in-flight call has finished, and the call delivers its result
+	close(release)
+	if err := calls.Wait(context.Background()); err != nil {
+		t.Errorf("Wait() error = %v", err)
+	}
+	if result := <-results; result.IsError {
+		t.Errorf("in-flight call result = %+v, want its own result", result)
+	}
+}
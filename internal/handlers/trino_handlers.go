@@ -1,58 +1,180 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/tuannvm/mcp-trino/internal/format"
 	"github.com/tuannvm/mcp-trino/internal/trino"
 )
 
+// defaultProgressInterval is the minimum time between progress notifications of a query
+const defaultProgressInterval = 2 * time.Second
+
 // TrinoHandlers contains all handlers for Trino-related tools
 type TrinoHandlers struct {
-	TrinoClient *trino.Client
+	Clusters *trino.Clusters
+	// DefaultFormat is the result format used when a call does not name one
+	DefaultFormat string
+	// ProgressInterval is the minimum time between progress notifications of a query
+	ProgressInterval time.Duration
 }
 
 // NewTrinoHandlers creates a new set of Trino handlers
-func NewTrinoHandlers(client *trino.Client) *TrinoHandlers {
+func NewTrinoHandlers(clusters *trino.Clusters) *TrinoHandlers {
 	return &TrinoHandlers{
-		TrinoClient: client,
+		Clusters:         clusters,
+		DefaultFormat:    format.JSON,
+		ProgressInterval: defaultProgressInterval,
 	}
 }
 
 // ExecuteQuery handles query execution
 func (h *TrinoHandlers) ExecuteQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract the query parameter
-	query, ok := request.Params.Arguments["query"].(string)
+	query, ok := request.GetArguments()["query"].(string)
 	if !ok {
 		mcpErr := fmt.Errorf("query parameter must be a string")
 		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
 	}
+	client, err := h.client(request.GetArguments())
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
 
-	// Execute the query - SQL injection protection is handled within the client
-	results, err := h.TrinoClient.ExecuteQuery(query)
+	// Extract the optional page size and output format
+	pageSize, err := intArgument(request.GetArguments(), "page_size")
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+	formatName, err := h.formatArgument(request.GetArguments())
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+
+	// Execute the query - SQL injection protection is handled within the client. Its
+	// progress is reported while it runs when the client sent a progress token.
+	ctx, stopProgress := h.withProgress(ctx, request)
+	results, err := client.ExecuteQueryPaged(ctx, query, pageSize)
+	stopProgress()
+	if refused := refusedResult(err); refused != nil {
+		return refused, nil
+	}
 	if err != nil {
 		log.Printf("Error executing query: %v", err)
 		mcpErr := fmt.Errorf("query execution failed: %w", err)
 		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
 	}
 
-	// Convert results to JSON string for display
-	jsonData, err := json.MarshalIndent(results, "", "  ")
+	return pageResult(results, formatName), nil
+}
+
+// SubmitQuery queues a query for background execution and returns its job ID
+func (h *TrinoHandlers) SubmitQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query, ok := request.GetArguments()["query"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("query parameter must be a string")
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+	client, err := h.client(request.GetArguments())
 	if err != nil {
-		mcpErr := fmt.Errorf("failed to marshal results to JSON: %w", err)
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+	pageSize, err := intArgument(request.GetArguments(), "page_size")
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+
+	status, err := client.SubmitQuery(ctx, query, pageSize)
+	if refused := refusedResult(err); refused != nil {
+		return refused, nil
+	}
+	if err != nil {
+		log.Printf("Error submitting query: %v", err)
+		mcpErr := fmt.Errorf("failed to submit query: %w", err)
 		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
 	}
 
-	// Return the results as formatted JSON text
-	return mcp.NewToolResultText(string(jsonData)), nil
+	return jobResult(status, format.JSON), nil
+}
+
+// GetQueryJob reports the state and progress of a background job and, once it has
+// succeeded, the first page of its result
+func (h *TrinoHandlers) GetQueryJob(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id, ok := request.GetArguments()["job_id"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("job_id parameter must be a string")
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+	formatName, err := h.formatArgument(request.GetArguments())
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+
+	cluster, err := clusterArgument(request.GetArguments())
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+	status, err := h.Clusters.QueryJob(ctx, cluster, id)
+	if err != nil {
+		log.Printf("Error getting query job: %v", err)
+		mcpErr := fmt.Errorf("failed to get query job: %w", err)
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+
+	return jobResult(status, formatName), nil
+}
+
+// FetchResults handles fetching further pages of a paginated query result
+func (h *TrinoHandlers) FetchResults(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	handle, ok := request.GetArguments()["handle"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("handle parameter must be a string")
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+	pageToken, ok := request.GetArguments()["page_token"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("page_token parameter must be a string")
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+	pageSize, err := intArgument(request.GetArguments(), "page_size")
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+	formatName, err := h.formatArgument(request.GetArguments())
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+
+	cluster, err := clusterArgument(request.GetArguments())
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+	page, err := h.Clusters.FetchResults(ctx, cluster, handle, pageToken, pageSize)
+	if err != nil {
+		log.Printf("Error fetching results: %v", err)
+		mcpErr := fmt.Errorf("failed to fetch results: %w", err)
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+
+	return pageResult(page, formatName), nil
 }
 
 // ListCatalogs handles catalog listing
 func (h *TrinoHandlers) ListCatalogs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	catalogs, err := h.TrinoClient.ListCatalogs()
+	client, err := h.client(request.GetArguments())
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+
+	catalogs, err := client.ListCatalogs(ctx)
 	if err != nil {
 		log.Printf("Error listing catalogs: %v", err)
 		mcpErr := fmt.Errorf("failed to list catalogs: %w", err)
@@ -73,11 +195,16 @@ func (h *TrinoHandlers) ListCatalogs(ctx context.Context, request mcp.CallToolRe
 func (h *TrinoHandlers) ListSchemas(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract catalog parameter (optional)
 	var catalog string
-	if catalogParam, ok := request.Params.Arguments["catalog"].(string); ok {
+	if catalogParam, ok := request.GetArguments()["catalog"].(string); ok {
 		catalog = catalogParam
 	}
 
-	schemas, err := h.TrinoClient.ListSchemas(catalog)
+	client, err := h.client(request.GetArguments())
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+
+	schemas, err := client.ListSchemas(ctx, catalog)
 	if err != nil {
 		log.Printf("Error listing schemas: %v", err)
 		mcpErr := fmt.Errorf("failed to list schemas: %w", err)
@@ -98,14 +225,19 @@ func (h *TrinoHandlers) ListSchemas(ctx context.Context, request mcp.CallToolReq
 func (h *TrinoHandlers) ListTables(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract catalog and schema parameters (optional)
 	var catalog, schema string
-	if catalogParam, ok := request.Params.Arguments["catalog"].(string); ok {
+	if catalogParam, ok := request.GetArguments()["catalog"].(string); ok {
 		catalog = catalogParam
 	}
-	if schemaParam, ok := request.Params.Arguments["schema"].(string); ok {
+	if schemaParam, ok := request.GetArguments()["schema"].(string); ok {
 		schema = schemaParam
 	}
 
-	tables, err := h.TrinoClient.ListTables(catalog, schema)
+	client, err := h.client(request.GetArguments())
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+
+	tables, err := client.ListTables(ctx, catalog, schema)
 	if err != nil {
 		log.Printf("Error listing tables: %v", err)
 		mcpErr := fmt.Errorf("failed to list tables: %w", err)
@@ -128,22 +260,31 @@ func (h *TrinoHandlers) GetTableSchema(ctx context.Context, request mcp.CallTool
 	var catalog, schema string
 	var table string
 
-	if catalogParam, ok := request.Params.Arguments["catalog"].(string); ok {
+	if catalogParam, ok := request.GetArguments()["catalog"].(string); ok {
 		catalog = catalogParam
 	}
-	if schemaParam, ok := request.Params.Arguments["schema"].(string); ok {
+	if schemaParam, ok := request.GetArguments()["schema"].(string); ok {
 		schema = schemaParam
 	}
 
 	// Table parameter is required
-	tableParam, ok := request.Params.Arguments["table"].(string)
+	tableParam, ok := request.GetArguments()["table"].(string)
 	if !ok {
 		mcpErr := fmt.Errorf("table parameter is required")
 		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
 	}
 	table = tableParam
+	include, err := stringsArgument(request.GetArguments(), "include")
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
 
-	tableSchema, err := h.TrinoClient.GetTableSchema(catalog, schema, table)
+	client, err := h.client(request.GetArguments())
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+
+	tableSchema, err := client.DescribeTable(ctx, catalog, schema, table, include)
 	if err != nil {
 		log.Printf("Error getting table schema: %v", err)
 		mcpErr := fmt.Errorf("failed to get table schema: %w", err)
@@ -159,3 +300,417 @@ func (h *TrinoHandlers) GetTableSchema(ctx context.Context, request mcp.CallTool
 
 	return mcp.NewToolResultText(string(jsonData)), nil
 }
+
+// ProfileTable handles profiling the value distribution of a table's columns
+func (h *TrinoHandlers) ProfileTable(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+	table, ok := args["table"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("table parameter is required")
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+	catalog, _ := args["catalog"].(string)
+	schema, _ := args["schema"].(string)
+
+	var opts trino.ProfileOptions
+	var err error
+	if opts.Columns, err = stringsArgument(args, "columns"); err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+	if value, ok := args["sample_percent"]; ok && value != nil {
+		if opts.SamplePercent, ok = value.(float64); !ok {
+			mcpErr := fmt.Errorf("sample_percent parameter must be a number")
+			return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		}
+	}
+	if opts.SampleRows, err = intArgument(args, "sample_rows"); err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+	if opts.TopValues, err = intArgument(args, "top_n"); err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+
+	client, err := h.client(args)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+
+	profile, err := client.ProfileTable(ctx, catalog, schema, table, opts)
+	if refused := refusedResult(err); refused != nil {
+		return refused, nil
+	}
+	if err != nil {
+		log.Printf("Error profiling table: %v", err)
+		mcpErr := fmt.Errorf("failed to profile table: %w", err)
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+
+	// Convert the profile to JSON string for display
+	jsonData, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal table profile to JSON: %w", err)
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// SearchMetadata handles searching table and column names and comments
+func (h *TrinoHandlers) SearchMetadata(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+	pattern, ok := args["pattern"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("pattern parameter must be a string")
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+	search := trino.MetadataSearch{Pattern: pattern}
+	search.Catalog, _ = args["catalog"].(string)
+	search.Schema, _ = args["schema"].(string)
+	search.Kind, _ = args["kind"].(string)
+	limit, err := intArgument(args, "limit")
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+	search.Limit = limit
+
+	client, err := h.client(args)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+
+	result, err := client.SearchMetadata(ctx, search)
+	if err != nil {
+		log.Printf("Error searching metadata: %v", err)
+		mcpErr := fmt.Errorf("failed to search metadata: %w", err)
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+
+	// Convert the matches to JSON string for display
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal search result to JSON: %w", err)
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// EstimateQueryCost reports the planner's cost estimate of a query and the cost limits
+// it exceeds, without running it
+func (h *TrinoHandlers) EstimateQueryCost(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query, ok := request.GetArguments()["query"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("query parameter must be a string")
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+	client, err := h.client(request.GetArguments())
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+
+	estimate, err := client.EstimateQueryCost(ctx, query)
+	if refused := refusedResult(err); refused != nil {
+		return refused, nil
+	}
+	if err != nil {
+		log.Printf("Error estimating query cost: %v", err)
+		mcpErr := fmt.Errorf("failed to estimate query cost: %w", err)
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+
+	// Convert the estimate to JSON string for display
+	jsonData, err := json.MarshalIndent(estimate, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal cost estimate to JSON: %w", err)
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// refusedResult reports a query refused by the statement policy or the cost limits, or
+// returns nil for any other error
+func refusedResult(err error) *mcp.CallToolResult {
+	var violation *trino.PolicyViolationError
+	if errors.As(err, &violation) {
+		log.Printf("Query refused by policy: %v", violation)
+		return policyViolationResult(violation)
+	}
+	var costErr *trino.CostLimitError
+	if errors.As(err, &costErr) {
+		log.Printf("Query refused by cost preflight: %v", costErr)
+		return costLimitResult(costErr)
+	}
+	return nil
+}
+
+// costLimitResult reports a query refused by the cost preflight as a structured JSON
+// error with a readable message, the estimate and a hint
+func costLimitResult(costErr *trino.CostLimitError) *mcp.CallToolResult {
+	payload := struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+		*trino.CostLimitError
+	}{
+		Error:          "cost_limit_exceeded",
+		Message:        costErr.Error(),
+		CostLimitError: costErr,
+	}
+	jsonData, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(costErr.Error(), costErr)
+	}
+	return mcp.NewToolResultError(string(jsonData))
+}
+
+// policyViolationResult reports a refused statement as a structured JSON error so
+// the caller can see which kind of statement and which rule were involved
+func policyViolationResult(violation *trino.PolicyViolationError) *mcp.CallToolResult {
+	payload := struct {
+		Error string `json:"error"`
+		*trino.PolicyViolationError
+	}{
+		Error:                "policy_violation",
+		PolicyViolationError: violation,
+	}
+	jsonData, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(violation.Error(), violation)
+	}
+	return mcp.NewToolResultError(string(jsonData))
+}
+
+// ListClusters reports the configured clusters and their health
+func (h *TrinoHandlers) ListClusters(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	statuses := h.Clusters.Status(ctx)
+
+	// Convert statuses to JSON string for display
+	jsonData, err := json.MarshalIndent(statuses, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal clusters to JSON: %w", err)
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// RefreshMetadata handles invalidating cached catalog, schema and table metadata
+func (h *TrinoHandlers) RefreshMetadata(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+	cluster, err := clusterArgument(args)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+	catalog, _ := args["catalog"].(string)
+	schema, _ := args["schema"].(string)
+	table, _ := args["table"].(string)
+
+	refreshes, err := h.Clusters.RefreshMetadata(cluster, catalog, schema, table)
+	if err != nil {
+		log.Printf("Error refreshing metadata: %v", err)
+		mcpErr := fmt.Errorf("failed to refresh metadata: %w", err)
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+
+	// Convert the refreshes to JSON string for display
+	jsonData, err := json.MarshalIndent(refreshes, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal metadata refresh to JSON: %w", err)
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// QueryStatus reports the state of a query, or lists the running queries when no
+// query ID is given
+func (h *TrinoHandlers) QueryStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	client, err := h.client(request.GetArguments())
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+
+	var status interface{}
+	if queryID, _ := request.GetArguments()["query_id"].(string); queryID != "" {
+		info, err := client.QueryStatus(ctx, queryID)
+		if err != nil {
+			log.Printf("Error getting query status: %v", err)
+			mcpErr := fmt.Errorf("failed to get query status: %w", err)
+			return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+		}
+		status = info
+	} else {
+		status = client.RunningQueries(ctx)
+	}
+
+	// Convert the status to JSON string for display
+	jsonData, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal query status to JSON: %w", err)
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// KillQuery cancels a query started by this server
+func (h *TrinoHandlers) KillQuery(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	queryID, ok := request.GetArguments()["query_id"].(string)
+	if !ok {
+		mcpErr := fmt.Errorf("query_id parameter must be a string")
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+	client, err := h.client(request.GetArguments())
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err), nil
+	}
+
+	if err := client.KillQuery(ctx, queryID); err != nil {
+		log.Printf("Error killing query: %v", err)
+		mcpErr := fmt.Errorf("failed to kill query: %w", err)
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr), nil
+	}
+	log.Printf("Killed query %s", queryID)
+
+	return mcp.NewToolResultText(fmt.Sprintf("Query %s was cancelled", queryID)), nil
+}
+
+// client returns the client of the cluster named by the optional cluster argument
+func (h *TrinoHandlers) client(args map[string]interface{}) (*trino.Client, error) {
+	name, err := clusterArgument(args)
+	if err != nil {
+		return nil, err
+	}
+	return h.Clusters.Get(name)
+}
+
+// clusterArgument reads the optional cluster argument; empty means the default cluster,
+// or every cluster for tools that span them
+func clusterArgument(args map[string]interface{}) (string, error) {
+	value, ok := args["cluster"]
+	if !ok || value == nil {
+		return "", nil
+	}
+	name, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("cluster parameter must be a string")
+	}
+	return name, nil
+}
+
+// pageMetadata describes a page rendered in a non-JSON format
+type pageMetadata struct {
+	Columns       []trino.Column `json:"columns"`
+	RowCount      int            `json:"row_count"`
+	Truncated     bool           `json:"truncated"`
+	Hint          string         `json:"hint,omitempty"`
+	Offset        int            `json:"offset"`
+	TotalRows     int            `json:"total_rows"`
+	Handle        string         `json:"handle,omitempty"`
+	NextPageToken string         `json:"next_page_token,omitempty"`
+}
+
+// pageResult renders a result page with the encoder of its format, followed by a JSON
+// block with columns and paging details
+func pageResult(page *trino.ResultPage, formatName string) *mcp.CallToolResult {
+	enc, err := format.Get(formatName)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(err.Error(), err)
+	}
+	var body bytes.Buffer
+	if err := enc.Encode(&body, &page.QueryResult); err != nil {
+		mcpErr := fmt.Errorf("failed to encode results as %s: %w", formatName, err)
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr)
+	}
+	metadata, err := json.Marshal(pageMetadata{
+		Columns:       page.Columns,
+		RowCount:      page.RowCount,
+		Truncated:     page.Truncated,
+		Hint:          page.Hint,
+		Offset:        page.Offset,
+		TotalRows:     page.TotalRows,
+		Handle:        page.Handle,
+		NextPageToken: page.NextPageToken,
+	})
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal result metadata to JSON: %w", err)
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.NewTextContent(body.String()),
+			mcp.NewTextContent(string(metadata)),
+		},
+	}
+}
+
+// jobResult renders a job's status, followed by its result as rendered by pageResult
+func jobResult(status *trino.JobStatus, formatName string) *mcp.CallToolResult {
+	page := status.Result
+	status.Result = nil
+	jsonData, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		mcpErr := fmt.Errorf("failed to marshal job status to JSON: %w", err)
+		return mcp.NewToolResultErrorFromErr(mcpErr.Error(), mcpErr)
+	}
+	result := mcp.NewToolResultText(string(jsonData))
+	if page != nil {
+		rendered := pageResult(page, formatName)
+		if rendered.IsError {
+			return rendered
+		}
+		result.Content = append(result.Content, rendered.Content...)
+	}
+	return result
+}
+
+// formatArgument extracts the optional output format, falling back to the default
+func (h *TrinoHandlers) formatArgument(args map[string]interface{}) (string, error) {
+	name := h.DefaultFormat
+	if value, ok := args["format"]; ok && value != nil {
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("format parameter must be a string")
+		}
+		name = s
+	}
+	if name == "" {
+		return format.JSON, nil
+	}
+	if _, err := format.Get(name); err != nil {
+		return "", err
+	}
+	return strings.ToLower(name), nil
+}
+
+// stringsArgument extracts an optional array of strings argument
+func stringsArgument(args map[string]interface{}, name string) ([]string, error) {
+	value, ok := args[name]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s parameter must be an array of strings", name)
+	}
+	values := make([]string, len(items))
+	for i, item := range items {
+		if values[i], ok = item.(string); !ok {
+			return nil, fmt.Errorf("%s parameter must be an array of strings", name)
+		}
+	}
+	return values, nil
+}
+
+// intArgument extracts an optional non-negative integer argument; JSON numbers arrive as float64
+func intArgument(args map[string]interface{}, name string) (int, error) {
+	value, ok := args[name]
+	if !ok || value == nil {
+		return 0, nil
+	}
+	number, ok := value.(float64)
+	if !ok || number < 0 || number != float64(int(number)) {
+		return 0, fmt.Errorf("%s parameter must be a non-negative integer", name)
+	}
+	return int(number), nil
+}
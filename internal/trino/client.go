@@ -5,11 +5,13 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"net"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
-	_ "github.com/trinodb/trino-go-client/trino"
+	"github.com/trinodb/trino-go-client/trino"
 	"github.com/tuannvm/mcp-trino/internal/config"
 )
 
@@ -17,21 +19,36 @@ import (
 type Client struct {
 	db      *sql.DB
 	config  *config.TrinoConfig
+	policy  *Policy
+	results *resultStore
 	timeout time.Duration
+	// clientName is the HTTP client registered with the driver for this cluster
+	clientName string
+	inflight   inflightQueries
+	jobs       *jobRunner
+	metadata   *metadataCache
 }
 
 // NewClient creates a new Trino client
 func NewClient(cfg *config.TrinoConfig) (*Client, error) {
-	dsn := fmt.Sprintf("%s://%s:%s@%s:%d?catalog=%s&schema=%s&SSL=%t&SSLInsecure=%t",
-		cfg.Scheme,
-		url.QueryEscape(cfg.User),
-		url.QueryEscape(cfg.Password),
-		cfg.Host,
-		cfg.Port,
-		url.QueryEscape(cfg.Catalog),
-		url.QueryEscape(cfg.Schema),
-		cfg.SSL,
-		cfg.SSLInsecure)
+	policy := DefaultPolicy(cfg.AllowWriteQueries)
+	if cfg.PolicyFile != "" {
+		var err error
+		if policy, err = LoadPolicy(cfg.PolicyFile); err != nil {
+			return nil, err
+		}
+		log.Printf("Loaded statement policy from %s (%d rules, default %s)", cfg.PolicyFile, len(policy.Rules), policy.Default)
+	}
+
+	clientName, err := registerHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	dsn, err := dataSourceName(cfg, clientName)
+	if err != nil {
+		trino.DeregisterCustomClient(clientName)
+		return nil, err
+	}
 
 	// The Trino driver registers itself with database/sql on import
 	// We can just use sql.Open directly with the trino driver
@@ -39,6 +56,7 @@ func NewClient(cfg *config.TrinoConfig) (*Client, error) {
 	// Open a connection
 	db, err := sql.Open("trino", dsn)
 	if err != nil {
+		trino.DeregisterCustomClient(clientName)
 		return nil, fmt.Errorf("failed to connect to Trino: %w", err)
 	}
 
@@ -48,97 +66,233 @@ func NewClient(cfg *config.TrinoConfig) (*Client, error) {
 	db.SetConnMaxLifetime(5 * time.Minute)
 
 	// Test the connection
-	if err := db.Ping(); err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.QueryTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
 		closeErr := db.Close()
 		if closeErr != nil {
 			log.Printf("Error closing DB connection: %v", closeErr)
 		}
+		trino.DeregisterCustomClient(clientName)
 		return nil, fmt.Errorf("failed to ping Trino: %w", err)
 	}
 
-	return &Client{
-		db:      db,
-		config:  cfg,
-		timeout: cfg.QueryTimeout,
-	}, nil
+	c := &Client{
+		db:         db,
+		config:     cfg,
+		policy:     policy,
+		results:    newResultStore(cfg.MaxResultHandles, cfg.ResultStoreBytes, cfg.ResultTTL),
+		timeout:    cfg.QueryTimeout,
+		clientName: clientName,
+		metadata:   newMetadataCache(cfg.MetadataCacheTTL, cfg.MetadataCacheSize),
+	}
+	c.jobs = newJobRunner(c, cfg.JobWorkers, cfg.JobQueueSize, cfg.JobTimeout, cfg.JobTTL)
+	return c, nil
+}
+
+// dataSourceName builds the driver DSN for the configured authentication method
+func dataSourceName(cfg *config.TrinoConfig, clientName string) (string, error) {
+	// The password is only sent with basic authentication; the driver drops it over http
+	user := url.User(cfg.User)
+	if cfg.Password != "" && (cfg.Auth == "" || cfg.Auth == config.AuthBasic) {
+		user = url.UserPassword(cfg.User, cfg.Password)
+	}
+	server := url.URL{
+		Scheme: cfg.Scheme,
+		User:   user,
+		Host:   net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+	}
+	dsnConfig := &trino.Config{
+		ServerURI:        server.String(),
+		Catalog:          cfg.Catalog,
+		Schema:           cfg.Schema,
+		CustomClientName: clientName,
+	}
+	if cfg.Auth == config.AuthKerberos {
+		dsnConfig.KerberosEnabled = "true"
+		dsnConfig.KerberosKeytabPath = cfg.KerberosKeytab
+		dsnConfig.KerberosPrincipal = cfg.KerberosPrincipal
+		dsnConfig.KerberosRealm = cfg.KerberosRealm
+		dsnConfig.KerberosConfigPath = cfg.KerberosConfig
+		dsnConfig.KerberosRemoteServiceName = cfg.KerberosService
+	}
+	return dsnConfig.FormatDSN()
 }
 
-// Close closes the database connection
+// Close releases buffered results, closes the database connection and unregisters
+// the cluster's HTTP client
 func (c *Client) Close() error {
-	return c.db.Close()
+	c.jobs.close()
+	c.results.close()
+	err := c.db.Close()
+	trino.DeregisterCustomClient(c.clientName)
+	return err
 }
 
-// isReadOnlyQuery checks if the SQL query is read-only (SELECT, SHOW, DESCRIBE, EXPLAIN)
-// This helps prevent SQL injection attacks by restricting the types of queries allowed
-func isReadOnlyQuery(query string) bool {
-	// Convert to lowercase for case-insensitive comparison and normalize whitespace
-	queryLower := strings.ToLower(strings.TrimSpace(query))
-	
-	// Replace any newline characters with spaces to normalize the query format
-	queryLower = strings.ReplaceAll(queryLower, "\n", " ")
-	queryLower = strings.ReplaceAll(queryLower, "\r", " ")
-	
-	// Ensure there's at least one space after keywords for proper prefix matching
-	if strings.HasPrefix(queryLower, "select") && !strings.HasPrefix(queryLower, "select ") {
-		queryLower = "select " + queryLower[6:]
+type userKey struct{}
+
+// WithUser returns a context whose queries run as the given Trino user. The user is sent
+// in the X-Trino-User header, so the configured TRINO_USER must be allowed to impersonate it.
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// userFromContext returns the user set by WithUser, or "" for the configured user
+func userFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(userKey{}).(string)
+	return user
+}
+
+// Ping checks that the coordinator answers a trivial query. It bypasses the statement
+// policy, which may not allow any query at all.
+func (c *Client) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	rows, err := c.db.QueryContext(ctx, "SELECT 1",
+		sql.Named("X-Trino-Progress-Callback", trino.ProgressUpdater(noProgress{})),
+		sql.Named("X-Trino-Progress-Callback-Period", progressPeriod))
+	if err != nil {
+		return err
 	}
-	if strings.HasPrefix(queryLower, "show") && !strings.HasPrefix(queryLower, "show ") {
-		queryLower = "show " + queryLower[4:]
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("Error closing rows: %v", err)
+		}
+	}()
+	for rows.Next() {
 	}
-	if strings.HasPrefix(queryLower, "describe") && !strings.HasPrefix(queryLower, "describe ") {
-		queryLower = "describe " + queryLower[8:]
+	return rows.Err()
+}
+
+// isReadOnlyQuery checks if the SQL query is a single read-only statement (SELECT, SHOW, DESCRIBE, EXPLAIN)
+// This helps prevent SQL injection attacks by restricting the types of queries allowed
+func isReadOnlyQuery(query string) bool {
+	stmt, err := ClassifyStatement(query)
+	return err == nil && stmt.ReadOnly()
+}
+
+// ExecuteQuery executes a SQL query and returns the results, capped at the
+// configured maximum row count and result size. With cost limits configured, queries
+// estimated to exceed them are refused with a CostLimitError.
+func (c *Client) ExecuteQuery(ctx context.Context, query string) (*QueryResult, error) {
+	if err := c.preflight(ctx, query); err != nil {
+		return nil, err
 	}
-	if strings.HasPrefix(queryLower, "explain") && !strings.HasPrefix(queryLower, "explain ") {
-		queryLower = "explain " + queryLower[7:]
+	return c.execute(ctx, query, resultLimits{maxRows: c.config.MaxRows, maxBytes: c.config.MaxResultBytes})
+}
+
+// ExecuteQueryPaged executes a SQL query and returns its first page. Up to the configured
+// buffer size is read from Trino; when more rows remain than fit in one page they are kept
+// on the server and the page carries a handle and token for FetchResults.
+// A pageSize of zero or more than the configured maximum row count uses the maximum.
+// Like ExecuteQuery, it refuses queries estimated to exceed the cost limits.
+func (c *Client) ExecuteQueryPaged(ctx context.Context, query string, pageSize int) (*ResultPage, error) {
+	if err := c.preflight(ctx, query); err != nil {
+		return nil, err
 	}
-	if strings.HasPrefix(queryLower, "with") && !strings.HasPrefix(queryLower, "with ") {
-		queryLower = "with " + queryLower[4:]
+	result, err := c.execute(ctx, query, c.bufferLimits())
+	if err != nil {
+		return nil, err
 	}
 
-	// First check for SQL injection attempts with multiple statements
-	if strings.Contains(queryLower, ";") {
-		return false
+	p := page(result, 0, c.pageLimits(pageSize))
+	if p.NextPageToken != "" {
+		if p.Handle, err = c.results.put(result, userFromContext(ctx)); err != nil {
+			return nil, err
+		}
 	}
+	return p, nil
+}
+
+// bufferLimits bounds the results buffered on the server for pagination
+func (c *Client) bufferLimits() resultLimits {
+	return resultLimits{maxRows: c.config.ResultBufferRows, maxBytes: c.config.ResultBufferBytes}
+}
 
-	// Check for write operations anywhere in the query
-	writeOperations := []string{
-		"insert ", "update ", "delete ", "drop ", "create ", "alter ", "truncate ",
+// FetchResults returns the page of a buffered result starting at the given page token.
+// The buffer is released once its last page has been returned. Results are only
+// visible to the user that ran the query.
+func (c *Client) FetchResults(ctx context.Context, handle, pageToken string, pageSize int) (*ResultPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
-	
-	for _, op := range writeOperations {
-		if strings.Contains(queryLower, op) {
-			return false
-		}
+	offset, err := decodePageToken(pageToken)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.results.get(handle, userFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if offset >= len(result.Rows) {
+		return nil, fmt.Errorf("page token is past the end of the result")
 	}
 
-	// Check if query starts with SELECT, SHOW, DESCRIBE, EXPLAIN or WITH (for CTEs)
-	// These are generally read-only operations
-	readOnlyPrefixes := []string{
-		"select ", "show ", "describe ", "explain ", "with ",
+	p := page(result, offset, c.pageLimits(pageSize))
+	if p.NextPageToken == "" {
+		c.results.release(handle)
+	} else {
+		p.Handle = handle
 	}
+	return p, nil
+}
 
-	for _, prefix := range readOnlyPrefixes {
-		if strings.HasPrefix(queryLower, prefix) {
-			return true
-		}
+// pageLimits bounds a page by the requested size and the configured response limits
+func (c *Client) pageLimits(pageSize int) resultLimits {
+	limits := resultLimits{maxRows: c.config.MaxRows, maxBytes: c.config.MaxResultBytes}
+	if pageSize > 0 && pageSize < limits.maxRows {
+		limits.maxRows = pageSize
 	}
+	return limits
+}
+
+// checkPolicy refuses statements that cannot be classified or that the policy does not allow
+func (c *Client) checkPolicy(query string) error {
+	// SQL injection protection: the statement must be classifiable and allowed by the policy
+	// (read-only statements only, unless configured otherwise)
+	stmt, err := ClassifyStatement(query)
+	if err != nil {
+		return fmt.Errorf("security restriction: unable to classify statement: %w", err)
+	}
+	return c.policy.Check(stmt, c.config.Catalog, c.config.Schema)
+}
 
-	return false
+// execute checks the statement against the policy, runs it within the query timeout and
+// collects the rows within limits. Cancelling ctx stops the query on the coordinator as
+// well as locally.
+func (c *Client) execute(ctx context.Context, query string, limits resultLimits) (*QueryResult, error) {
+	return c.run(ctx, query, limits, c.timeout, nil)
 }
 
-// ExecuteQuery executes a SQL query and returns the results
-func (c *Client) ExecuteQuery(query string) ([]map[string]interface{}, error) {
-	// SQL injection protection: only allow read-only queries unless explicitly allowed in config
-	if !c.config.AllowWriteQueries && !isReadOnlyQuery(query) {
-		return nil, fmt.Errorf("security restriction: only SELECT, SHOW, DESCRIBE, and EXPLAIN queries are allowed. " +
-			"Set TRINO_ALLOW_WRITE_QUERIES=true to enable write operations (at your own risk)")
+// run is execute with an explicit timeout. started, when set, is called with the query
+// once it is registered, before it is sent to the coordinator.
+func (c *Client) run(ctx context.Context, query string, limits resultLimits, timeout time.Duration, started func(*runningQuery)) (*QueryResult, error) {
+	if err := c.checkPolicy(query); err != nil {
+		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	ctx, running, done, err := c.inflight.start(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	if started != nil {
+		started(running)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// Execute the query
-	rows, err := c.db.QueryContext(ctx, query)
+	// Execute the query, as the caller's user when one is set. The progress callback
+	// records the Trino query ID and statistics.
+	args := []interface{}{
+		sql.Named("X-Trino-Progress-Callback", trino.ProgressUpdater(running)),
+		sql.Named("X-Trino-Progress-Callback-Period", progressPeriod),
+	}
+	if user := userFromContext(ctx); user != "" {
+		args = append(args, sql.Named("X-Trino-User", user))
+	}
+	rows, err := c.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query execution failed: %w", err)
 	}
@@ -148,20 +302,29 @@ func (c *Client) ExecuteQuery(query string) ([]map[string]interface{}, error) {
 		}
 	}()
 
-	// Get column names
-	columns, err := rows.Columns()
+	// Get column descriptors
+	columnTypes, err := rows.ColumnTypes()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get column names: %w", err)
+		return nil, fmt.Errorf("failed to get column types: %w", err)
 	}
 
 	// Prepare result container
-	results := make([]map[string]interface{}, 0)
+	result := &QueryResult{Columns: columnsFromTypes(columnTypes), Rows: make([][]interface{}, 0)}
+	converters := valueConverters(result.Columns)
+	size := 0
 
 	// Iterate through rows
 	for rows.Next() {
+		// Stop once the row limit is reached; the remaining rows are discarded and
+		// closing the rows cancels the query on the coordinator
+		if limits.maxRows > 0 && len(result.Rows) >= limits.maxRows {
+			result.truncate(fmt.Sprintf("row limit of %d reached", limits.maxRows))
+			break
+		}
+
 		// Create a slice of interface{} to hold the values
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
+		values := make([]interface{}, len(columnTypes))
+		valuePtrs := make([]interface{}, len(columnTypes))
 
 		// Initialize the pointers
 		for i := range values {
@@ -173,15 +336,19 @@ func (c *Client) ExecuteQuery(query string) ([]map[string]interface{}, error) {
 			log.Printf("Error scanning row: %v", err)
 			continue
 		}
+		for i := range values {
+			values[i] = converters[i].convert(values[i])
+		}
 
-		// Create a map for the current row
-		rowMap := make(map[string]interface{})
-		for i, col := range columns {
-			val := values[i]
-			rowMap[col] = val
+		if limits.maxBytes > 0 {
+			size += serializedSize(values)
+			if size > limits.maxBytes {
+				result.truncate(fmt.Sprintf("result size limit of %d bytes reached", limits.maxBytes))
+				break
+			}
 		}
 
-		results = append(results, rowMap)
+		result.Rows = append(result.Rows, values)
 	}
 
 	// Check for errors after iterating
@@ -189,98 +356,164 @@ func (c *Client) ExecuteQuery(query string) ([]map[string]interface{}, error) {
 		return nil, fmt.Errorf("error iterating rows: %w", err)
 	}
 
-	return results, nil
+	result.RowCount = len(result.Rows)
+	result.size = size
+	return result, nil
 }
 
 // ListCatalogs returns a list of available catalogs
-func (c *Client) ListCatalogs() ([]string, error) {
-	results, err := c.ExecuteQuery("SHOW CATALOGS")
+func (c *Client) ListCatalogs(ctx context.Context) ([]string, error) {
+	return cachedMetadata(ctx, c, metadataKey{kind: metadataCatalogs}, func() ([]string, error) {
+		result, err := c.execute(ctx, "SHOW CATALOGS", resultLimits{})
+		if err != nil {
+			return nil, err
+		}
+
+		return result.Strings("Catalog"), nil
+	})
+}
+
+// ListSchemas returns a list of schemas in the specified catalog
+func (c *Client) ListSchemas(ctx context.Context, catalog string) ([]string, error) {
+	catalog, err := c.catalogName(catalog)
 	if err != nil {
 		return nil, err
 	}
 
-	catalogs := make([]string, 0, len(results))
-	for _, row := range results {
-		if catalog, ok := row["Catalog"].(string); ok {
-			catalogs = append(catalogs, catalog)
+	return cachedMetadata(ctx, c, metadataKey{kind: metadataSchemas, catalog: catalog}, func() ([]string, error) {
+		query := "SHOW SCHEMAS FROM " + QuoteIdentifier(catalog)
+		result, err := c.execute(ctx, query, resultLimits{})
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	return catalogs, nil
+		return result.Strings("Schema"), nil
+	})
 }
 
-// ListSchemas returns a list of schemas in the specified catalog
-func (c *Client) ListSchemas(catalog string) ([]string, error) {
-	if catalog == "" {
-		catalog = c.config.Catalog
+// ListTables returns a list of tables in the specified catalog and schema
+func (c *Client) ListTables(ctx context.Context, catalog, schema string) ([]string, error) {
+	catalog, schema, err := c.schemaName(catalog, schema)
+	if err != nil {
+		return nil, err
 	}
 
-	query := fmt.Sprintf("SHOW SCHEMAS FROM %s", catalog)
-	results, err := c.ExecuteQuery(query)
+	key := metadataKey{kind: metadataTables, catalog: catalog, schema: schema}
+	return cachedMetadata(ctx, c, key, func() ([]string, error) {
+		query := "SHOW TABLES FROM " + QuoteQualifiedName(catalog, schema)
+		result, err := c.execute(ctx, query, resultLimits{})
+		if err != nil {
+			return nil, err
+		}
+
+		return result.Strings("Table"), nil
+	})
+}
+
+// GetTableSchema returns the schema of a table. The table may be qualified with its
+// schema or catalog, e.g. tiny.nation or tpch.tiny.nation.
+func (c *Client) GetTableSchema(ctx context.Context, catalog, schema, table string) (*QueryResult, error) {
+	name, err := c.tableName(catalog, schema, table)
 	if err != nil {
 		return nil, err
 	}
 
-	schemas := make([]string, 0, len(results))
-	for _, row := range results {
-		if schema, ok := row["Schema"].(string); ok {
-			schemas = append(schemas, schema)
+	key := metadataKey{kind: metadataTable, catalog: name[0], schema: name[1], table: name[2]}
+	return cachedMetadata(ctx, c, key, func() (*QueryResult, error) {
+		query := "DESCRIBE " + QuoteQualifiedName(name...)
+		return c.execute(ctx, query, resultLimits{})
+	})
+}
+
+// ShowCreateTable returns the CREATE statement of a table or view
+func (c *Client) ShowCreateTable(ctx context.Context, catalog, schema, table string) (string, error) {
+	parts, err := c.tableName(catalog, schema, table)
+	if err != nil {
+		return "", err
+	}
+	name := QuoteQualifiedName(parts...)
+	result, err := c.execute(ctx, "SHOW CREATE TABLE "+name, resultLimits{})
+	if err != nil {
+		// SHOW CREATE TABLE fails for views; try the view form before giving up
+		viewResult, viewErr := c.execute(ctx, "SHOW CREATE VIEW "+name, resultLimits{})
+		if viewErr != nil {
+			return "", err
 		}
+		result = viewResult
 	}
-
-	return schemas, nil
+	if len(result.Rows) == 0 || len(result.Rows[0]) == 0 {
+		return "", fmt.Errorf("no CREATE statement returned for %s", name)
+	}
+	ddl, _ := result.Rows[0][0].(string)
+	return ddl, nil
 }
 
-// ListTables returns a list of tables in the specified catalog and schema
-func (c *Client) ListTables(catalog, schema string) ([]string, error) {
+// catalogName parses a catalog argument, defaulting to the configured catalog
+func (c *Client) catalogName(catalog string) (string, error) {
 	if catalog == "" {
 		catalog = c.config.Catalog
 	}
+	return parseIdentifier(catalog)
+}
+
+// schemaName parses catalog and schema arguments, defaulting to the configured ones
+func (c *Client) schemaName(catalog, schema string) (string, string, error) {
+	catalog, err := c.catalogName(catalog)
+	if err != nil {
+		return "", "", err
+	}
 	if schema == "" {
 		schema = c.config.Schema
 	}
+	schema, err = parseIdentifier(schema)
+	if err != nil {
+		return "", "", err
+	}
+	return catalog, schema, nil
+}
 
-	query := fmt.Sprintf("SHOW TABLES FROM %s.%s", catalog, schema)
-	results, err := c.ExecuteQuery(query)
+// tableName parses a table argument into its catalog, schema and table. Parts the
+// table is not qualified with come from the catalog and schema arguments, or the
+// configured defaults.
+func (c *Client) tableName(catalog, schema, table string) ([]string, error) {
+	parts, err := ParseQualifiedName(table, 3)
 	if err != nil {
 		return nil, err
 	}
-
-	tables := make([]string, 0, len(results))
-	for _, row := range results {
-		if table, ok := row["Table"].(string); ok {
-			tables = append(tables, table)
+	switch len(parts) {
+	case 3:
+		return parts, nil
+	case 2:
+		catalog, err := c.catalogName(catalog)
+		if err != nil {
+			return nil, err
 		}
+		return []string{catalog, parts[0], parts[1]}, nil
 	}
-
-	return tables, nil
+	catalog, schema, err = c.schemaName(catalog, schema)
+	if err != nil {
+		return nil, err
+	}
+	return []string{catalog, schema, parts[0]}, nil
 }
 
-// GetTableSchema returns the schema of a table
-func (c *Client) GetTableSchema(catalog, schema, table string) ([]map[string]interface{}, error) {
-	// Check if table already contains a fully qualified name (catalog.schema.table)
-	parts := strings.Split(table, ".")
-	if len(parts) == 3 {
-		// If table is already fully qualified, use it directly
-		query := fmt.Sprintf("DESCRIBE %s", table)
-		return c.ExecuteQuery(query)
-	} else if len(parts) == 2 {
-		// If table has schema.table format
-		schema = parts[0]
-		table = parts[1]
-		if catalog == "" {
-			catalog = c.config.Catalog
-		}
-	} else {
-		// Use provided or default catalog and schema
-		if catalog == "" {
-			catalog = c.config.Catalog
-		}
-		if schema == "" {
-			schema = c.config.Schema
-		}
+// GetTableComment returns the comment of a table, or an empty string if it has none
+func (c *Client) GetTableComment(ctx context.Context, catalog, schema, table string) (string, error) {
+	query := fmt.Sprintf("SELECT comment FROM system.metadata.table_comments "+
+		"WHERE catalog_name = %s AND schema_name = %s AND table_name = %s",
+		quoteLiteral(catalog), quoteLiteral(schema), quoteLiteral(table))
+	result, err := c.execute(ctx, query, resultLimits{maxRows: 1})
+	if err != nil {
+		return "", err
+	}
+	comments := result.Strings("comment")
+	if len(comments) == 0 {
+		return "", nil
 	}
+	return comments[0], nil
+}
 
-	query := fmt.Sprintf("DESCRIBE %s.%s.%s", catalog, schema, table)
-	return c.ExecuteQuery(query)
+// quoteLiteral renders s as a SQL string literal
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
 }
@@ -1,7 +1,12 @@
 package trino
 
 import (
+	"context"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
 )
 
 func TestIsReadOnlyQuery(t *testing.T) {
@@ -86,21 +91,21 @@ func TestIsReadOnlyQuery(t *testing.T) {
 			expected: true,
 		},
 
-		// Keywords without spaces
+		// Keywords run into identifiers are lexed as a single identifier, not a keyword
 		{
 			name:     "SELECT without space after keyword",
 			query:    "SELECTid, name FROM users",
-			expected: true,
+			expected: false,
 		},
 		{
 			name:     "SHOW without space after keyword",
 			query:    "SHOWtables",
-			expected: true,
+			expected: false,
 		},
 		{
 			name:     "DESCRIBE without space after keyword",
 			query:    "DESCRIBEusers",
-			expected: true,
+			expected: false,
 		},
 
 		// Case insensitivity
@@ -152,6 +157,38 @@ func TestIsReadOnlyQuery(t *testing.T) {
 			expected: false,
 		},
 
+		// Keywords inside literals, quoted identifiers and comments are ignored
+		{
+			name:     "SELECT with write keyword in string literal",
+			query:    "SELECT 'update ' AS x",
+			expected: true,
+		},
+		{
+			name:     "SELECT with write keyword in line comment",
+			query:    "-- drop table users\nSELECT 1",
+			expected: true,
+		},
+		{
+			name:     "SELECT with write keyword in block comment",
+			query:    "SELECT /* delete from users */ 1",
+			expected: true,
+		},
+		{
+			name:     "SELECT with quoted identifier named like a keyword",
+			query:    `SELECT "drop ", "insert" FROM users`,
+			expected: true,
+		},
+		{
+			name:     "SELECT with trailing semicolon",
+			query:    "SELECT 1;",
+			expected: true,
+		},
+		{
+			name:     "Semicolon inside string literal",
+			query:    "SELECT 'a;b' AS x",
+			expected: true,
+		},
+
 		// Sneaky write operations embedded in SELECT (should return false)
 		{
 			name:     "SELECT with embedded INSERT",
@@ -179,3 +216,264 @@ func TestIsReadOnlyQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteQuery(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	srv.SetResult("SELECT id FROM users", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "id", Type: "bigint"}},
+		Rows:    [][]interface{}{{1}, {2}, {3}},
+	})
+
+	cfg := srv.Config()
+	cfg.MaxRows = 2
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	result, err := client.ExecuteQuery(context.Background(), "SELECT id FROM users")
+	if err != nil {
+		t.Fatalf("ExecuteQuery() error = %v", err)
+	}
+	if result.RowCount != 2 || !result.Truncated || result.Hint == "" {
+		t.Errorf("ExecuteQuery() = %d rows, truncated %v, hint %q; want 2 rows, truncated with hint",
+			result.RowCount, result.Truncated, result.Hint)
+	}
+}
+
+func TestExecuteQueryCancellation(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	srv.SetResult("SELECT * FROM huge", trinotest.Result{Block: true})
+
+	client, err := NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err = client.ExecuteQuery(ctx, "SELECT * FROM huge")
+	if err == nil {
+		t.Fatal("ExecuteQuery() succeeded, want cancellation error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("ExecuteQuery() returned after %v, want prompt return on cancel", elapsed)
+	}
+
+	// The driver cancels the query on the coordinator
+	requests := srv.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("server received %d statements, want 1", len(requests))
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, id := range srv.Cancelled() {
+			if id == requests[0].QueryID {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("query %s was not cancelled on the coordinator (cancelled: %v)", requests[0].QueryID, srv.Cancelled())
+}
+
+func TestExecuteQueryColumns(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	srv.SetResult("SELECT * FROM orders", trinotest.Result{
+		Columns: []trinotest.Column{
+			{Name: "id", Type: "bigint"},
+			{Name: "total", Type: "decimal(38,2)"},
+			{Name: "status", Type: "varchar"},
+			{Name: "code", Type: "varchar(10)"},
+			{Name: "created_at", Type: "timestamp(3) with time zone"},
+			{Name: "tags", Type: "array(varchar)"},
+			{Name: "id", Type: "integer"},
+		},
+		Rows: [][]interface{}{
+			{1, "10.50", "open", "A1", "2024-01-01 00:00:00.000 UTC", []interface{}{"x"}, 7},
+		},
+	})
+
+	client, err := NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	result, err := client.ExecuteQuery(context.Background(), "SELECT * FROM orders")
+	if err != nil {
+		t.Fatalf("ExecuteQuery() error = %v", err)
+	}
+
+	want := []Column{
+		{Name: "id", Type: "bigint"},
+		{Name: "total", Type: "decimal(38,2)"},
+		{Name: "status", Type: "varchar"},
+		{Name: "code", Type: "varchar(10)"},
+		{Name: "created_at", Type: "timestamp(3) with time zone"},
+		{Name: "tags", Type: "array(varchar)"},
+		{Name: "id", Type: "integer"},
+	}
+	if !reflect.DeepEqual(result.Columns, want) {
+		t.Errorf("Columns = %+v, want %+v", result.Columns, want)
+	}
+	if len(result.Rows) != 1 || len(result.Rows[0]) != len(want) {
+		t.Fatalf("Rows = %v, want one row of %d values", result.Rows, len(want))
+	}
+	// Duplicate column names keep their own positions
+	if result.Rows[0][0] != int64(1) || result.Rows[0][6] != int64(7) {
+		t.Errorf("Rows[0] = %v, want id values 1 and 7 in order", result.Rows[0])
+	}
+}
+
+func TestShowCreateTable(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	srv.SetResult(`SHOW CREATE TABLE "tpch"."tiny"."nation"`, trinotest.Result{
+		Columns: []trinotest.Column{{Name: "Create Table", Type: "varchar"}},
+		Rows:    [][]interface{}{{"CREATE TABLE tpch.tiny.nation (nationkey bigint)"}},
+	})
+	srv.SetResult(`SHOW CREATE TABLE "tpch"."tiny"."v"`, trinotest.Result{Error: "Relation 'tpch.tiny.v' is a view, not a table"})
+	srv.SetResult(`SHOW CREATE VIEW "tpch"."tiny"."v"`, trinotest.Result{
+		Columns: []trinotest.Column{{Name: "Create View", Type: "varchar"}},
+		Rows:    [][]interface{}{{"CREATE VIEW tpch.tiny.v AS SELECT 1"}},
+	})
+	srv.SetResult("SELECT comment FROM system.metadata.table_comments "+
+		"WHERE catalog_name = 'tpch' AND schema_name = 'tiny' AND table_name = 'it''s'", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "comment", Type: "varchar"}},
+		Rows:    [][]interface{}{{"Quoted"}},
+	})
+
+	client, err := NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	if got, err := client.ShowCreateTable(ctx, "tpch", "tiny", "nation"); err != nil || got != "CREATE TABLE tpch.tiny.nation (nationkey bigint)" {
+		t.Errorf("ShowCreateTable(nation) = %q, %v", got, err)
+	}
+	if got, err := client.ShowCreateTable(ctx, "tpch", "tiny", "v"); err != nil || got != "CREATE VIEW tpch.tiny.v AS SELECT 1" {
+		t.Errorf("ShowCreateTable(v) = %q, %v; want the view definition", got, err)
+	}
+	if _, err := client.ShowCreateTable(ctx, "tpch", "tiny", "missing"); err == nil {
+		t.Error("ShowCreateTable(missing) succeeded, want error")
+	}
+	if got, err := client.GetTableComment(ctx, "tpch", "tiny", "it's"); err != nil || got != "Quoted" {
+		t.Errorf("GetTableComment() = %q, %v; want \"Quoted\"", got, err)
+	}
+}
+
+func TestExecuteQueryAsUser(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	srv.SetResult("SELECT 1", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "_col0", Type: "integer"}},
+		Rows:    [][]interface{}{{1}},
+	})
+
+	client, err := NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery() error = %v", err)
+	}
+	if _, err := client.ExecuteQuery(WithUser(context.Background(), "alice"), "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery() as alice error = %v", err)
+	}
+
+	requests := srv.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("server received %d statements, want 2", len(requests))
+	}
+	if got := requests[0].Header.Get("X-Trino-User"); got != "test" {
+		t.Errorf("X-Trino-User = %q, want the configured user", got)
+	}
+	if got := requests[1].Header.Get("X-Trino-User"); got != "alice" {
+		t.Errorf("X-Trino-User = %q, want the impersonated user", got)
+	}
+}
+
+func TestMetadataQuoting(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+
+	client, err := NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantSQL string
+	}{
+		{
+			name:    "default catalog",
+			call:    func() error { _, err := client.ListSchemas(ctx, ""); return err },
+			wantSQL: `SHOW SCHEMAS FROM "memory"`,
+		},
+		{
+			name:    "injection in catalog",
+			call:    func() error { _, err := client.ListSchemas(ctx, `"tpch; DROP TABLE x"`); return err },
+			wantSQL: `SHOW SCHEMAS FROM "tpch; DROP TABLE x"`,
+		},
+		{
+			name:    "hyphenated and upper-case schema",
+			call:    func() error { _, err := client.ListTables(ctx, "Hive", `"My-Schema"`); return err },
+			wantSQL: `SHOW TABLES FROM "hive"."My-Schema"`,
+		},
+		{
+			name:    "table with defaults",
+			call:    func() error { _, err := client.GetTableSchema(ctx, "", "", "orders"); return err },
+			wantSQL: `DESCRIBE "memory"."default"."orders"`,
+		},
+		{
+			name:    "schema-qualified table",
+			call:    func() error { _, err := client.GetTableSchema(ctx, "tpch", "ignored", "tiny.nation"); return err },
+			wantSQL: `DESCRIBE "tpch"."tiny"."nation"`,
+		},
+		{
+			name:    "fully qualified table with a dot in its name",
+			call:    func() error { _, err := client.GetTableSchema(ctx, "", "", `hive.web."events.2024"`); return err },
+			wantSQL: `DESCRIBE "hive"."web"."events.2024"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fake coordinator has no results registered; only the SQL matters
+			_ = tt.call()
+			requests := srv.Requests()
+			if len(requests) == 0 || requests[len(requests)-1].SQL != tt.wantSQL {
+				t.Errorf("last statement = %v, want %s", requests, tt.wantSQL)
+			}
+		})
+	}
+
+	// Invalid names are refused before a statement is sent
+	before := len(srv.Requests())
+	for _, table := range []string{"tpch; DROP TABLE x.y.z.w", `a"b`, "a..b", "a.b.c.d"} {
+		if _, err := client.GetTableSchema(ctx, "", "", table); err == nil {
+			t.Errorf("GetTableSchema(%q) succeeded, want error", table)
+		}
+	}
+	if _, err := client.ListTables(ctx, "tpch", "tiny.extra"); err == nil {
+		t.Error("ListTables() with a dotted schema succeeded, want error")
+	}
+	if after := len(srv.Requests()); after != before {
+		t.Errorf("invalid names sent %d statements, want none", after-before)
+	}
+}
@@ -0,0 +1,197 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// ErrUnknownCluster is returned when a call names a cluster that is not configured
+var ErrUnknownCluster = errors.New("unknown cluster")
+
+// Clusters is a registry of clients for named Trino clusters. Each cluster has its own
+// connection pool, statement policy and result buffers.
+type Clusters struct {
+	clients     map[string]*Client
+	names       []string
+	defaultName string
+}
+
+// ClusterStatus reports the configuration and health of a cluster
+type ClusterStatus struct {
+	Name      string `json:"name"`
+	Host      string `json:"host"`
+	Port      int    `json:"port"`
+	Catalog   string `json:"catalog"`
+	Schema    string `json:"schema"`
+	Default   bool   `json:"default"`
+	Healthy   bool   `json:"healthy"`
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+	// MetadataCache counts the lookups of the cluster's metadata cache
+	MetadataCache MetadataCacheStats `json:"metadata_cache"`
+}
+
+// NewClusters creates a client for every configured cluster
+func NewClusters(cfg *config.ClustersConfig) (*Clusters, error) {
+	c := &Clusters{clients: make(map[string]*Client), defaultName: cfg.Default}
+	for _, cluster := range cfg.Clusters {
+		client, err := NewClient(cluster.TrinoConfig)
+		if err != nil {
+			_ = c.Close()
+			return nil, fmt.Errorf("cluster %s: %w", cluster.Name, err)
+		}
+		c.clients[cluster.Name] = client
+		c.names = append(c.names, cluster.Name)
+	}
+	return c, nil
+}
+
+// Get returns the client of the named cluster, or of the default cluster when name is empty
+func (c *Clusters) Get(name string) (*Client, error) {
+	if name == "" {
+		name = c.defaultName
+	}
+	client, ok := c.clients[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q (configured: %s)", ErrUnknownCluster, name, strings.Join(c.names, ", "))
+	}
+	return client, nil
+}
+
+// Default returns the client of the default cluster
+func (c *Clusters) Default() *Client {
+	return c.clients[c.defaultName]
+}
+
+// DefaultName returns the name of the default cluster
+func (c *Clusters) DefaultName() string {
+	return c.defaultName
+}
+
+// Names returns the cluster names in configuration order
+func (c *Clusters) Names() []string {
+	return append([]string(nil), c.names...)
+}
+
+// FetchResults returns a page of a buffered result. Handles are unique across clusters,
+// so when no cluster is named every cluster is searched for the handle.
+func (c *Clusters) FetchResults(ctx context.Context, cluster, handle, pageToken string, pageSize int) (*ResultPage, error) {
+	if cluster != "" {
+		client, err := c.Get(cluster)
+		if err != nil {
+			return nil, err
+		}
+		return client.FetchResults(ctx, handle, pageToken, pageSize)
+	}
+	for _, name := range c.names {
+		page, err := c.clients[name].FetchResults(ctx, handle, pageToken, pageSize)
+		if !errors.Is(err, ErrResultNotFound) {
+			return page, err
+		}
+	}
+	return nil, ErrResultNotFound
+}
+
+// QueryJob finds a background job on the named cluster, or on any cluster when none is named
+func (c *Clusters) QueryJob(ctx context.Context, cluster, id string) (*JobStatus, error) {
+	if cluster != "" {
+		client, err := c.Get(cluster)
+		if err != nil {
+			return nil, err
+		}
+		return client.QueryJob(ctx, id)
+	}
+	for _, name := range c.names {
+		status, err := c.clients[name].QueryJob(ctx, id)
+		if !errors.Is(err, ErrJobNotFound) {
+			return status, err
+		}
+	}
+	return nil, ErrJobNotFound
+}
+
+// RefreshMetadata invalidates cached metadata on the named cluster, or on every cluster
+// when none is named; see Client.RefreshMetadata
+func (c *Clusters) RefreshMetadata(cluster, catalog, schema, table string) ([]MetadataRefresh, error) {
+	names := c.names
+	if cluster != "" {
+		if _, err := c.Get(cluster); err != nil {
+			return nil, err
+		}
+		names = []string{cluster}
+	}
+	refreshes := make([]MetadataRefresh, 0, len(names))
+	for _, name := range names {
+		client := c.clients[name]
+		n, err := client.RefreshMetadata(catalog, schema, table)
+		if err != nil {
+			return nil, err
+		}
+		refreshes = append(refreshes, MetadataRefresh{Cluster: name, Invalidated: n, Cache: client.MetadataCacheStats()})
+	}
+	return refreshes, nil
+}
+
+// Status checks every cluster concurrently and reports its health
+func (c *Clusters) Status(ctx context.Context) []ClusterStatus {
+	statuses := make([]ClusterStatus, len(c.names))
+	var wg sync.WaitGroup
+	for i, name := range c.names {
+		client := c.clients[name]
+		statuses[i] = ClusterStatus{
+			Name:    name,
+			Host:    client.config.Host,
+			Port:    client.config.Port,
+			Catalog: client.config.Catalog,
+			Schema:  client.config.Schema,
+			Default: name == c.defaultName,
+
+			MetadataCache: client.MetadataCacheStats(),
+		}
+		wg.Add(1)
+		go func(status *ClusterStatus) {
+			defer wg.Done()
+			start := time.Now()
+			err := client.Ping(ctx)
+			status.LatencyMS = time.Since(start).Milliseconds()
+			status.Healthy = err == nil
+			if err != nil {
+				status.Error = err.Error()
+			}
+		}(&statuses[i])
+	}
+	wg.Wait()
+	return statuses
+}
+
+// Shutdown shuts down every cluster client concurrently; see Client.Shutdown
+func (c *Clusters) Shutdown(ctx context.Context) error {
+	errs := make([]error, len(c.names))
+	var wg sync.WaitGroup
+	for i, name := range c.names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = c.clients[name].Shutdown(ctx, name)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Close closes every cluster client
+func (c *Clusters) Close() error {
+	var errs []error
+	for _, name := range c.names {
+		if err := c.clients[name].Close(); err != nil {
+			errs = append(errs, fmt.Errorf("cluster %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,68 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+func TestClusters(t *testing.T) {
+	prod, adhoc := trinotest.NewServer(), trinotest.NewServer()
+	defer prod.Close()
+	one := trinotest.Result{Columns: []trinotest.Column{{Name: "_col0", Type: "integer"}}, Rows: [][]interface{}{{1}}}
+	prod.SetResult("SELECT 1", one)
+	many := trinotest.Result{Columns: []trinotest.Column{{Name: "n", Type: "integer"}}}
+	for i := 0; i < 10; i++ {
+		many.Rows = append(many.Rows, []interface{}{i})
+	}
+	adhoc.SetResult("SELECT n FROM t", many)
+
+	clusters, err := NewClusters(&config.ClustersConfig{
+		Default: "prod",
+		Clusters: []config.ClusterConfig{
+			{Name: "prod", TrinoConfig: prod.Config()},
+			{Name: "adhoc", TrinoConfig: adhoc.Config()},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClusters() error = %v", err)
+	}
+	defer clusters.Close()
+
+	if client, err := clusters.Get(""); err != nil || client != clusters.Default() {
+		t.Errorf("Get(\"\") = %v, %v; want the default client", client, err)
+	}
+	if _, err := clusters.Get("staging"); !errors.Is(err, ErrUnknownCluster) {
+		t.Errorf("Get(staging) error = %v, want ErrUnknownCluster", err)
+	}
+
+	// A handle is found without naming the cluster that buffered it
+	client, _ := clusters.Get("adhoc")
+	first, err := client.ExecuteQueryPaged(context.Background(), "SELECT n FROM t", 4)
+	if err != nil || first.Handle == "" {
+		t.Fatalf("ExecuteQueryPaged() = %+v, %v; want a paginated result", first, err)
+	}
+	next, err := clusters.FetchResults(context.Background(), "", first.Handle, first.NextPageToken, 4)
+	if err != nil || next.Offset != 4 {
+		t.Errorf("FetchResults() = %+v, %v; want the second page", next, err)
+	}
+	if _, err := clusters.FetchResults(context.Background(), "", "unknown", first.NextPageToken, 4); !errors.Is(err, ErrResultNotFound) {
+		t.Errorf("FetchResults(unknown) error = %v, want ErrResultNotFound", err)
+	}
+
+	// Health is reported per cluster: adhoc goes down while prod stays up
+	adhoc.Close()
+	statuses := clusters.Status(context.Background())
+	if len(statuses) != 2 {
+		t.Fatalf("Status() returned %d clusters, want 2", len(statuses))
+	}
+	if s := statuses[0]; s.Name != "prod" || !s.Default || !s.Healthy {
+		t.Errorf("Status()[0] = %+v, want healthy default prod", s)
+	}
+	if s := statuses[1]; s.Name != "adhoc" || s.Healthy || s.Error == "" {
+		t.Errorf("Status()[1] = %+v, want unhealthy adhoc with an error", s)
+	}
+}
@@ -0,0 +1,390 @@
+package trino
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Values are converted to JSON as follows:
+//
+//	boolean                          true / false
+//	tinyint, smallint, integer       number
+//	bigint                           number, or a string outside ±(2^53-1) where JSON numbers lose precision
+//	real, double                     number; "NaN", "Infinity" and "-Infinity" as strings
+//	decimal                          string, e.g. "12345678901234567890.12"
+//	varchar, char, uuid, ipaddress   string
+//	json                             embedded JSON value
+//	varbinary                        {"encoding": "base64", "data": "..."}
+//	date                             "2006-01-02"
+//	time                             "15:04:05.000"
+//	time with time zone              "15:04:05.000+01:00"
+//	timestamp                        "2006-01-02T15:04:05.000" (no zone, as stored)
+//	timestamp with time zone         RFC 3339 with offset, "2006-01-02T15:04:05.000+01:00"
+//	array                            array of converted elements
+//	map                              object keyed by the map key rendered as a string
+//	row                              object with fields in declaration order; array if fields are unnamed
+//
+// Fractional seconds are rendered with the precision of the column type.
+// Other types are passed through as the driver returns them.
+
+// maxSafeInteger is the largest integer a JSON number holds exactly in common decoders
+const maxSafeInteger = 1<<53 - 1
+
+// BinaryValue is the JSON representation of varbinary values
+type BinaryValue struct {
+	Encoding string `json:"encoding"`
+	Data     string `json:"data"`
+}
+
+// RowValue is a row value whose fields are serialized in declaration order
+type RowValue struct {
+	Fields []string
+	Values []interface{}
+}
+
+// MarshalJSON implements json.Marshaler
+func (r RowValue) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range r.Fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(r.Values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// trinoType is a parsed Trino type such as "decimal(10,2)" or "map(varchar, array(bigint))"
+type trinoType struct {
+	// name is the base type, e.g. "decimal" or "timestamp with time zone"
+	name string
+	// precision is the fractional second precision of time types, -1 when unspecified
+	precision int
+	// elems are the element type of an array, the key and value types of a map
+	// or the field types of a row
+	elems []trinoType
+	// fields are the row field names; empty strings for anonymous fields
+	fields []string
+}
+
+// defaultTimePrecision is the precision Trino uses for time types declared without one
+const defaultTimePrecision = 3
+
+// parseType parses a Trino type name as reported in column metadata
+func parseType(s string) (trinoType, error) {
+	s = strings.TrimSpace(s)
+	t := trinoType{name: s, precision: -1}
+	open := strings.IndexByte(s, '(')
+	if open < 0 {
+		return t, nil
+	}
+	end := strings.LastIndexByte(s, ')')
+	if end < open {
+		return t, fmt.Errorf("malformed type %q", s)
+	}
+	t.name = strings.TrimSpace(s[:open])
+	// "timestamp(3) with time zone" carries its suffix after the parameters
+	if suffix := strings.TrimSpace(s[end+1:]); suffix != "" {
+		t.name += " " + suffix
+	}
+
+	args := splitTypeArgs(s[open+1 : end])
+	switch t.name {
+	case "array", "map":
+		for _, arg := range args {
+			elem, err := parseType(arg)
+			if err != nil {
+				return t, err
+			}
+			t.elems = append(t.elems, elem)
+		}
+		if (t.name == "array" && len(t.elems) != 1) || (t.name == "map" && len(t.elems) != 2) {
+			return t, fmt.Errorf("malformed type %q", s)
+		}
+	case "row":
+		for _, arg := range args {
+			name, fieldType := splitRowField(arg)
+			elem, err := parseType(fieldType)
+			if err != nil {
+				return t, err
+			}
+			t.fields = append(t.fields, name)
+			t.elems = append(t.elems, elem)
+		}
+	case "time", "timestamp", "time with time zone", "timestamp with time zone":
+		if len(args) == 1 {
+			p, err := strconv.Atoi(args[0])
+			if err != nil {
+				return t, fmt.Errorf("malformed type %q", s)
+			}
+			t.precision = p
+		}
+	}
+	return t, nil
+}
+
+// splitTypeArgs splits type parameters on commas outside parentheses and quotes
+func splitTypeArgs(s string) []string {
+	var args []string
+	depth, start, quoted := 0, 0, false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(args, strings.TrimSpace(s[start:]))
+}
+
+// splitRowField separates a row field declaration into its name and type.
+// Anonymous fields, as in "row(bigint, varchar)", have an empty name.
+func splitRowField(field string) (name, fieldType string) {
+	if strings.HasPrefix(field, `"`) {
+		for i := 1; i < len(field); i++ {
+			if field[i] != '"' {
+				continue
+			}
+			if i+1 < len(field) && field[i+1] == '"' {
+				i++
+				continue
+			}
+			return strings.ReplaceAll(field[1:i], `""`, `"`), strings.TrimSpace(field[i+1:])
+		}
+		return "", field
+	}
+
+	sp := strings.IndexByte(field, ' ')
+	if sp < 0 || strings.ContainsRune(field[:sp], '(') {
+		return "", field
+	}
+	rest := strings.TrimSpace(field[sp+1:])
+	for _, suffix := range []string{"with time zone", "day to second", "year to month"} {
+		if strings.HasPrefix(rest, suffix) {
+			return "", field
+		}
+	}
+	return field[:sp], rest
+}
+
+// convert maps a value returned by the driver to its JSON representation. Top-level
+// values arrive as Go types (int64, time.Time, []byte); values nested in arrays, maps
+// and rows arrive as decoded JSON (json.Number and strings) and are converted the same way.
+func (t trinoType) convert(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	switch t.name {
+	case "bigint":
+		return convertBigint(v)
+	case "real", "double":
+		return convertFloat(v)
+	case "decimal":
+		return fmt.Sprint(v)
+	case "json":
+		if s, ok := v.(string); ok && json.Valid([]byte(s)) {
+			return json.RawMessage(s)
+		}
+	case "varbinary":
+		switch b := v.(type) {
+		case []byte:
+			return BinaryValue{Encoding: "base64", Data: base64.StdEncoding.EncodeToString(b)}
+		case string:
+			// Nested binary values are already base64 on the wire
+			return BinaryValue{Encoding: "base64", Data: b}
+		}
+	case "date", "time", "time with time zone", "timestamp", "timestamp with time zone":
+		return t.convertTime(v)
+	case "array":
+		if values, ok := v.([]interface{}); ok {
+			out := make([]interface{}, len(values))
+			for i, elem := range values {
+				out[i] = t.elems[0].convert(elem)
+			}
+			return out
+		}
+	case "map":
+		if entries, ok := v.(map[string]interface{}); ok {
+			out := make(map[string]interface{}, len(entries))
+			for key, value := range entries {
+				out[key] = t.elems[1].convert(value)
+			}
+			return out
+		}
+	case "row":
+		if values, ok := v.([]interface{}); ok && len(values) == len(t.elems) {
+			return t.convertRow(values)
+		}
+	}
+	return v
+}
+
+func (t trinoType) convertRow(values []interface{}) interface{} {
+	out := make([]interface{}, len(values))
+	named := true
+	for i, value := range values {
+		out[i] = t.elems[i].convert(value)
+		if t.fields[i] == "" {
+			named = false
+		}
+	}
+	if !named {
+		return out
+	}
+	return RowValue{Fields: t.fields, Values: out}
+}
+
+func convertBigint(v interface{}) interface{} {
+	var n int64
+	switch x := v.(type) {
+	case int64:
+		n = x
+	case json.Number:
+		var err error
+		if n, err = x.Int64(); err != nil {
+			return x.String()
+		}
+	default:
+		return v
+	}
+	if n > maxSafeInteger || n < -maxSafeInteger {
+		return strconv.FormatInt(n, 10)
+	}
+	return n
+}
+
+func convertFloat(v interface{}) interface{} {
+	var f float64
+	switch x := v.(type) {
+	case float64:
+		f = x
+	case json.Number:
+		// JSON numbers are already valid output; keep their exact digits
+		return x
+	case string:
+		// Trino sends non-finite values as strings
+		return x
+	default:
+		return v
+	}
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return f
+}
+
+func (t trinoType) convertTime(v interface{}) interface{} {
+	tm, ok := v.(time.Time)
+	if !ok {
+		s, isString := v.(string)
+		if !isString {
+			return v
+		}
+		parsed, err := parseTrinoTime(t.name, s)
+		if err != nil {
+			// Keep the coordinator's rendering rather than dropping the value
+			return s
+		}
+		tm = parsed
+	}
+	return tm.Format(t.timeLayout())
+}
+
+// timeLayout returns the output layout for a time type at its precision
+func (t trinoType) timeLayout() string {
+	if t.name == "date" {
+		return time.DateOnly
+	}
+	precision := t.precision
+	if precision < 0 {
+		precision = defaultTimePrecision
+	}
+	clock := "15:04:05"
+	if precision > 0 {
+		clock += "." + strings.Repeat("0", precision)
+	}
+	switch t.name {
+	case "time":
+		return clock
+	case "time with time zone":
+		return clock + "Z07:00"
+	case "timestamp":
+		return "2006-01-02T" + clock
+	default:
+		return "2006-01-02T" + clock + "Z07:00"
+	}
+}
+
+// parseTrinoTime parses the coordinator's text form of a time value,
+// e.g. "2024-01-01 12:00:00.000 Europe/Berlin" or "12:00:00.000+01:00"
+func parseTrinoTime(name, s string) (time.Time, error) {
+	const fraction = ".999999999"
+	switch name {
+	case "date":
+		return time.Parse(time.DateOnly, s)
+	case "time":
+		return time.Parse("15:04:05"+fraction, s)
+	case "timestamp":
+		return time.Parse("2006-01-02 15:04:05"+fraction, s)
+	case "time with time zone":
+		return time.Parse("15:04:05"+fraction+"-07:00", s)
+	}
+
+	sp := strings.LastIndexByte(s, ' ')
+	if sp < 0 {
+		return time.Time{}, fmt.Errorf("missing time zone in %q", s)
+	}
+	stamp, zone := s[:sp], s[sp+1:]
+	if strings.HasPrefix(zone, "+") || strings.HasPrefix(zone, "-") {
+		return time.Parse("2006-01-02 15:04:05"+fraction+" -07:00", s)
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.ParseInLocation("2006-01-02 15:04:05"+fraction, stamp, loc)
+}
+
+// valueConverters returns a converter per column. Types that cannot be parsed
+// are passed through unchanged.
+func valueConverters(columns []Column) []trinoType {
+	converters := make([]trinoType, len(columns))
+	for i, col := range columns {
+		t, err := parseType(col.Type)
+		if err != nil {
+			t = trinoType{name: col.Type, precision: -1}
+		}
+		converters[i] = t
+	}
+	return converters
+}
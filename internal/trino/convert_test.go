@@ -0,0 +1,90 @@
+package trino
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestConvertValue(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		typeName string
+		value    interface{}
+		want     string
+	}{
+		{"null", "bigint", nil, `null`},
+		{"boolean", "boolean", true, `true`},
+		{"integer", "integer", int64(42), `42`},
+		{"bigint safe", "bigint", int64(9007199254740991), `9007199254740991`},
+		{"bigint large", "bigint", int64(9223372036854775807), `"9223372036854775807"`},
+		{"bigint large negative", "bigint", int64(-9007199254740993), `"-9007199254740993"`},
+		{"double", "double", 1.5, `1.5`},
+		{"double NaN", "double", math.NaN(), `"NaN"`},
+		{"double infinity", "double", math.Inf(1), `"Infinity"`},
+		{"real negative infinity", "real", math.Inf(-1), `"-Infinity"`},
+		{"decimal", "decimal(38,2)", "12345678901234567890123456789012345.67", `"12345678901234567890123456789012345.67"`},
+		{"varchar", "varchar(10)", "hello", `"hello"`},
+		{"json", "json", `{"a":[1,2]}`, `{"a":[1,2]}`},
+		{"json invalid", "json", `not json`, `"not json"`},
+		{"varbinary", "varbinary", []byte("hi!"), `{"encoding":"base64","data":"aGkh"}`},
+		{"date", "date", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), `"2024-02-29"`},
+		{"time", "time(6)", time.Date(0, 1, 1, 13, 4, 5, 123456000, time.UTC), `"13:04:05.123456"`},
+		{"time with time zone", "time(3) with time zone",
+			time.Date(0, 1, 1, 13, 4, 5, 0, time.FixedZone("", 3600)), `"13:04:05.000+01:00"`},
+		{"timestamp", "timestamp(3)", time.Date(2024, 1, 2, 3, 4, 5, 6000000, time.Local), `"2024-01-02T03:04:05.006"`},
+		{"timestamp precision 0", "timestamp(0)", time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local), `"2024-01-02T03:04:05"`},
+		{"timestamp with time zone", "timestamp(3) with time zone",
+			time.Date(2024, 7, 1, 12, 0, 0, 0, berlin), `"2024-07-01T12:00:00.000+02:00"`},
+		{"timestamp with time zone UTC", "timestamp(6) with time zone",
+			time.Date(2024, 1, 1, 0, 0, 0, 500, time.UTC), `"2024-01-01T00:00:00.000000Z"`},
+		{"array", "array(bigint)",
+			[]interface{}{json.Number("1"), nil, json.Number("9223372036854775807")}, `[1,null,"9223372036854775807"]`},
+		{"array of decimals", "array(decimal(10,2))", []interface{}{"1.10", "2.25"}, `["1.10","2.25"]`},
+		{"array of doubles", "array(double)", []interface{}{json.Number("1.5"), "NaN"}, `[1.5,"NaN"]`},
+		{"array of varbinary", "array(varbinary)", []interface{}{"aGkh"}, `[{"encoding":"base64","data":"aGkh"}]`},
+		{"array of timestamps", "array(timestamp(3) with time zone)",
+			[]interface{}{"2024-07-01 12:00:00.000 Europe/Berlin", "2024-07-01 12:00:00.000 +05:30"},
+			`["2024-07-01T12:00:00.000+02:00","2024-07-01T12:00:00.000+05:30"]`},
+		{"array of dates", "array(date)", []interface{}{"2024-02-29"}, `["2024-02-29"]`},
+		{"map", "map(varchar, array(integer))",
+			map[string]interface{}{"b": []interface{}{json.Number("2")}, "a": []interface{}{}}, `{"a":[],"b":[2]}`},
+		{"row", "row(z bigint, a varchar, m row(x double))",
+			[]interface{}{json.Number("1"), "x", []interface{}{"Infinity"}}, `{"z":1,"a":"x","m":{"x":"Infinity"}}`},
+		{"row quoted field", `row("Two Words" integer, "say ""hi""" varchar)`,
+			[]interface{}{json.Number("1"), "x"}, `{"Two Words":1,"say \"hi\"":"x"}`},
+		{"row anonymous fields", "row(bigint, timestamp(3) with time zone)",
+			[]interface{}{json.Number("1"), "2024-01-01 00:00:00.000 UTC"}, `[1,"2024-01-01T00:00:00.000Z"]`},
+		{"unknown type", "hyperloglog", "AgwB", `"AgwB"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ, err := parseType(tt.typeName)
+			if err != nil {
+				t.Fatalf("parseType(%q) error = %v", tt.typeName, err)
+			}
+			got, err := json.Marshal(typ.convert(tt.value))
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("convert(%s, %v) = %s, want %s", tt.typeName, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTypeErrors(t *testing.T) {
+	for _, typeName := range []string{"array(bigint", "map(varchar)", "timestamp(x)"} {
+		if _, err := parseType(typeName); err == nil {
+			t.Errorf("parseType(%q) succeeded, want error", typeName)
+		}
+	}
+}
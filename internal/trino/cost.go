@@ -0,0 +1,226 @@
+package trino
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// costHint is returned with refused queries to steer the caller towards a cheaper query
+const costHint = "Filter on partition columns, read fewer tables or columns, and make sure every join has a " +
+	"join condition. estimate_query_cost shows the estimate of a revised query."
+
+// CostEstimate is the planner's estimate of what a query reads and produces, from
+// EXPLAIN (TYPE IO, FORMAT JSON). Estimates are nil when the planner has no statistics.
+type CostEstimate struct {
+	Tables     []TableEstimate `json:"tables"`
+	InputRows  *float64        `json:"input_rows"`
+	InputBytes *float64        `json:"input_bytes"`
+	OutputRows *float64        `json:"output_rows"`
+	CPUCost    *float64        `json:"cpu_cost,omitempty"`
+	MaxMemory  *float64        `json:"max_memory_bytes,omitempty"`
+	// Exceeded lists the configured limits the estimate exceeds
+	Exceeded []string `json:"exceeded,omitempty"`
+	// Warnings notes estimates that are missing, which the limits cannot be checked against
+	Warnings []string `json:"warnings,omitempty"`
+	Allowed  bool     `json:"allowed"`
+}
+
+// TableEstimate is the estimated input read from one table
+type TableEstimate struct {
+	Table string   `json:"table"`
+	Rows  *float64 `json:"rows"`
+	Bytes *float64 `json:"bytes"`
+}
+
+// CostLimitError is returned for queries whose estimated cost exceeds the configured limits
+type CostLimitError struct {
+	Estimate *CostEstimate `json:"estimate"`
+	Hint     string        `json:"hint"`
+}
+
+// Error implements the error interface
+func (e *CostLimitError) Error() string {
+	return "query refused by cost preflight: " + strings.Join(e.Estimate.Exceeded, "; ")
+}
+
+// planEstimate is a cost estimate in the IO plan. Trino writes unknown values as "NaN".
+type planEstimate struct {
+	OutputRowCount    estimateValue `json:"outputRowCount"`
+	OutputSizeInBytes estimateValue `json:"outputSizeInBytes"`
+	CPUCost           estimateValue `json:"cpuCost"`
+	MaxMemory         estimateValue `json:"maxMemory"`
+}
+
+// ioPlan is the output of EXPLAIN (TYPE IO, FORMAT JSON)
+type ioPlan struct {
+	InputTableColumnInfos []struct {
+		Table struct {
+			Catalog     string `json:"catalog"`
+			SchemaTable struct {
+				Schema string `json:"schema"`
+				Table  string `json:"table"`
+			} `json:"schemaTable"`
+		} `json:"table"`
+		Estimate planEstimate `json:"estimate"`
+	} `json:"inputTableColumnInfos"`
+	Estimate planEstimate `json:"estimate"`
+}
+
+// estimateValue is a planner estimate; nil when unknown
+type estimateValue struct {
+	value *float64
+}
+
+func (v *estimateValue) UnmarshalJSON(data []byte) error {
+	var f float64
+	if err := json.Unmarshal(data, &f); err != nil {
+		// NaN and infinities are written as strings
+		var s string
+		if json.Unmarshal(data, &s) != nil {
+			return fmt.Errorf("invalid estimate %s", data)
+		}
+		if f, err = strconv.ParseFloat(s, 64); err != nil {
+			return fmt.Errorf("invalid estimate %q", s)
+		}
+	}
+	if math.IsInf(f, 1) {
+		// JSON has no infinity; any limit is exceeded by the largest float
+		f = math.MaxFloat64
+	}
+	if !math.IsNaN(f) {
+		v.value = &f
+	}
+	return nil
+}
+
+// costLimit is a configured limit on one estimate
+type costLimit struct {
+	name  string
+	unit  string
+	limit int64
+	value *float64
+}
+
+// explainable reports whether the cost preflight applies to a statement. Only statements
+// that read tables are checked: queries, and INSERT and CREATE TABLE filled from a query.
+// SHOW, DESCRIBE and EXPLAIN are cheap.
+func explainable(stmt Statement) bool {
+	switch stmt.Verb {
+	case "SELECT", "WITH", "TABLE":
+		return stmt.Kind == StatementQuery
+	case "INSERT", "CREATE TABLE":
+		return stmt.FromQuery
+	}
+	return false
+}
+
+// costPreflightEnabled reports whether any cost limit is configured
+func (c *Client) costPreflightEnabled() bool {
+	return c.config.MaxEstimatedInputRows > 0 || c.config.MaxEstimatedInputBytes > 0 ||
+		c.config.MaxEstimatedOutputRows > 0
+}
+
+// EstimateQueryCost checks a query against the statement policy and returns the
+// planner's cost estimate, with the configured limits it exceeds
+func (c *Client) EstimateQueryCost(ctx context.Context, query string) (*CostEstimate, error) {
+	if err := c.checkPolicy(query); err != nil {
+		return nil, err
+	}
+	return c.estimateCost(ctx, query)
+}
+
+// preflight refuses a query whose estimated cost exceeds the configured limits with a
+// CostLimitError. Missing estimates do not refuse the query.
+func (c *Client) preflight(ctx context.Context, query string) error {
+	if !c.costPreflightEnabled() {
+		return nil
+	}
+	stmt, err := ClassifyStatement(query)
+	if err != nil || !explainable(stmt) {
+		return nil
+	}
+	// A write the policy refuses is reported as such rather than estimated
+	if err := c.policy.Check(stmt, c.config.Catalog, c.config.Schema); err != nil {
+		return err
+	}
+	estimate, err := c.estimateCost(ctx, query)
+	if err != nil {
+		return fmt.Errorf("cost preflight failed: %w", err)
+	}
+	if !estimate.Allowed {
+		return &CostLimitError{Estimate: estimate, Hint: costHint}
+	}
+	return nil
+}
+
+// estimateCost runs EXPLAIN (TYPE IO, FORMAT JSON) and sums the estimated input of
+// every table
+func (c *Client) estimateCost(ctx context.Context, query string) (*CostEstimate, error) {
+	result, err := c.execute(ctx, "EXPLAIN (TYPE IO, FORMAT JSON) "+query, resultLimits{maxRows: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Rows) == 0 || len(result.Rows[0]) == 0 {
+		return nil, fmt.Errorf("EXPLAIN returned no plan")
+	}
+	text, _ := result.Rows[0][0].(string)
+	var plan ioPlan
+	if err := json.Unmarshal([]byte(text), &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse IO plan: %w", err)
+	}
+	return c.newCostEstimate(&plan), nil
+}
+
+func (c *Client) newCostEstimate(plan *ioPlan) *CostEstimate {
+	estimate := &CostEstimate{
+		Tables:     make([]TableEstimate, 0, len(plan.InputTableColumnInfos)),
+		InputRows:  new(float64),
+		InputBytes: new(float64),
+		OutputRows: plan.Estimate.OutputRowCount.value,
+		CPUCost:    plan.Estimate.CPUCost.value,
+		MaxMemory:  plan.Estimate.MaxMemory.value,
+	}
+	for _, input := range plan.InputTableColumnInfos {
+		t := input.Table
+		table := TableEstimate{
+			Table: t.Catalog + "." + t.SchemaTable.Schema + "." + t.SchemaTable.Table,
+			Rows:  input.Estimate.OutputRowCount.value,
+			Bytes: input.Estimate.OutputSizeInBytes.value,
+		}
+		estimate.Tables = append(estimate.Tables, table)
+		// A table without statistics leaves the total unknown
+		if table.Rows == nil {
+			estimate.InputRows = nil
+			estimate.Warnings = append(estimate.Warnings, "no row estimate for "+table.Table+"; it may lack table statistics")
+		} else if estimate.InputRows != nil {
+			*estimate.InputRows += *table.Rows
+		}
+		if table.Bytes == nil {
+			estimate.InputBytes = nil
+			estimate.Warnings = append(estimate.Warnings, "no byte estimate for "+table.Table+"; it may lack table statistics")
+		} else if estimate.InputBytes != nil {
+			*estimate.InputBytes += *table.Bytes
+		}
+	}
+	if estimate.OutputRows == nil {
+		estimate.Warnings = append(estimate.Warnings, "no estimate of the rows the query produces")
+	}
+
+	limits := []costLimit{
+		{"input", "rows", c.config.MaxEstimatedInputRows, estimate.InputRows},
+		{"input", "bytes", c.config.MaxEstimatedInputBytes, estimate.InputBytes},
+		{"output", "rows", c.config.MaxEstimatedOutputRows, estimate.OutputRows},
+	}
+	for _, l := range limits {
+		if l.limit > 0 && l.value != nil && *l.value > float64(l.limit) {
+			estimate.Exceeded = append(estimate.Exceeded, fmt.Sprintf("estimated %s of %s %s exceeds the limit of %d %s",
+				l.name, strconv.FormatFloat(math.Round(*l.value), 'f', -1, 64), l.unit, l.limit, l.unit))
+		}
+	}
+	estimate.Allowed = len(estimate.Exceeded) == 0
+	return estimate
+}
@@ -0,0 +1,188 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+// ioPlanJSON builds an EXPLAIN (TYPE IO, FORMAT JSON) plan reading one table
+func ioPlanJSON(table, rows, bytes, outputRows string) string {
+	return `{
+  "inputTableColumnInfos" : [ {
+    "table" : { "catalog" : "hive", "schemaTable" : { "schema" : "sales", "table" : "` + table + `" } },
+    "columnConstraints" : [ ],
+    "estimate" : { "outputRowCount" : ` + rows + `, "outputSizeInBytes" : ` + bytes + `, "cpuCost" : 0.0, "maxMemory" : 0.0, "networkCost" : 0.0 }
+  } ],
+  "estimate" : { "outputRowCount" : ` + outputRows + `, "outputSizeInBytes" : "NaN", "cpuCost" : 1.0E12, "maxMemory" : 0.0, "networkCost" : 0.0 }
+}`
+}
+
+func TestCostPreflight(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	plans := map[string]string{
+		"SELECT * FROM orders":                     ioPlanJSON("orders", "1000.0", "64000.0", "1000.0"),
+		"SELECT * FROM orders CROSS JOIN lineitem": ioPlanJSON("lineitem", "6.0E9", "4.8E11", "6.0E12"),
+		"SELECT * FROM events":                     ioPlanJSON("events", `"NaN"`, `"NaN"`, `"NaN"`),
+	}
+	srv.HandleFunc(func(sql string) (trinotest.Result, bool) {
+		query, ok := strings.CutPrefix(sql, "EXPLAIN (TYPE IO, FORMAT JSON) ")
+		if !ok {
+			return trinotest.Result{
+				Columns: []trinotest.Column{{Name: "id", Type: "bigint"}},
+				Rows:    [][]interface{}{{1}},
+			}, true
+		}
+		plan, ok := plans[query]
+		return trinotest.Result{
+			Columns: []trinotest.Column{{Name: "Query Plan", Type: "varchar"}},
+			Rows:    [][]interface{}{{plan}},
+		}, ok
+	})
+
+	cfg := srv.Config()
+	cfg.MaxEstimatedInputBytes = 1 << 30
+	cfg.MaxEstimatedOutputRows = 1000000
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	if _, err := client.ExecuteQuery(ctx, "SELECT * FROM orders"); err != nil {
+		t.Errorf("ExecuteQuery() of a cheap query error = %v", err)
+	}
+
+	_, err = client.ExecuteQueryPaged(ctx, "SELECT * FROM orders CROSS JOIN lineitem", 0)
+	var costErr *CostLimitError
+	if !errors.As(err, &costErr) {
+		t.Fatalf("ExecuteQueryPaged() of a cross join error = %v, want CostLimitError", err)
+	}
+	if len(costErr.Estimate.Exceeded) != 2 || !strings.Contains(err.Error(), "estimated output of 6000000000000 rows") {
+		t.Errorf("CostLimitError = %v (exceeded %v), want the input bytes and output rows limits", err, costErr.Estimate.Exceeded)
+	}
+	for _, r := range srv.Requests() {
+		if r.SQL == "SELECT * FROM orders CROSS JOIN lineitem" {
+			t.Error("the refused query was sent to the coordinator")
+		}
+	}
+
+	// Without statistics the limits cannot be checked, so the query runs
+	if _, err := client.ExecuteQuery(ctx, "SELECT * FROM events"); err != nil {
+		t.Errorf("ExecuteQuery() without statistics error = %v", err)
+	}
+	estimate, err := client.EstimateQueryCost(ctx, "SELECT * FROM events")
+	if err != nil {
+		t.Fatalf("EstimateQueryCost() error = %v", err)
+	}
+	if !estimate.Allowed || estimate.InputRows != nil || estimate.InputBytes != nil || len(estimate.Warnings) != 3 {
+		t.Errorf("EstimateQueryCost() = %+v, want an allowed estimate with unknown input and warnings", estimate)
+	}
+
+	estimate, err = client.EstimateQueryCost(ctx, "SELECT * FROM orders")
+	if err != nil {
+		t.Fatalf("EstimateQueryCost() error = %v", err)
+	}
+	if len(estimate.Tables) != 1 || estimate.Tables[0].Table != "hive.sales.orders" ||
+		*estimate.InputRows != 1000 || *estimate.InputBytes != 64000 || !estimate.Allowed {
+		t.Errorf("EstimateQueryCost() = %+v, want 1000 rows and 64000 bytes from hive.sales.orders", estimate)
+	}
+
+	// Estimates go through the statement policy
+	var violation *PolicyViolationError
+	if _, err := client.EstimateQueryCost(ctx, "DELETE FROM orders"); !errors.As(err, &violation) {
+		t.Errorf("EstimateQueryCost() of a write error = %v, want a policy violation", err)
+	}
+}
+
+func TestCostPreflightWrites(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	srv.HandleFunc(func(sql string) (trinotest.Result, bool) {
+		if strings.HasPrefix(sql, "EXPLAIN (TYPE IO, FORMAT JSON) ") {
+			return trinotest.Result{
+				Columns: []trinotest.Column{{Name: "Query Plan", Type: "varchar"}},
+				Rows:    [][]interface{}{{ioPlanJSON("lineitem", "6.0E9", "4.8E11", "6.0E9")}},
+			}, true
+		}
+		return trinotest.Result{Columns: []trinotest.Column{{Name: "rows", Type: "bigint"}}, Rows: [][]interface{}{{1}}}, true
+	})
+
+	cfg := srv.Config()
+	cfg.AllowWriteQueries = true
+	cfg.MaxEstimatedInputRows = 1000000
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	// Writes filled from a query read its tables and are checked like the query
+	for _, query := range []string{
+		"INSERT INTO copy SELECT * FROM lineitem",
+		"CREATE TABLE copy AS SELECT * FROM lineitem",
+	} {
+		var costErr *CostLimitError
+		if _, err := client.ExecuteQuery(ctx, query); !errors.As(err, &costErr) {
+			t.Errorf("ExecuteQuery(%q) error = %v, want CostLimitError", query, err)
+		}
+	}
+
+	// Writes of literal rows and plain DDL read nothing and are not explained
+	for _, query := range []string{
+		"INSERT INTO copy VALUES (1)",
+		"CREATE TABLE copy (id bigint)",
+	} {
+		if _, err := client.ExecuteQuery(ctx, query); err != nil {
+			t.Errorf("ExecuteQuery(%q) error = %v", query, err)
+		}
+		if n := statements(srv, "EXPLAIN (TYPE IO, FORMAT JSON) "+query); n != 0 {
+			t.Errorf("ExecuteQuery(%q) ran the cost preflight", query)
+		}
+	}
+
+	// A write the policy refuses is not estimated
+	readOnly, err := NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer readOnly.Close()
+	readOnly.config.MaxEstimatedInputRows = 1000000
+	var violation *PolicyViolationError
+	if _, err := readOnly.ExecuteQuery(ctx, "INSERT INTO other SELECT * FROM lineitem"); !errors.As(err, &violation) {
+		t.Errorf("ExecuteQuery() of a refused write error = %v, want a policy violation", err)
+	}
+	if n := statements(srv, "EXPLAIN (TYPE IO, FORMAT JSON) INSERT INTO other SELECT * FROM lineitem"); n != 0 {
+		t.Error("a write the policy refuses was explained")
+	}
+}
+
+func TestCostPreflightDisabled(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	srv.SetResult("SELECT 1", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "_col0", Type: "integer"}},
+		Rows:    [][]interface{}{{1}},
+	})
+
+	client, err := NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery() error = %v", err)
+	}
+	for _, r := range srv.Requests() {
+		if strings.HasPrefix(r.SQL, "EXPLAIN") {
+			t.Errorf("preflight ran without cost limits: %s", r.SQL)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package trino
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// QuoteIdentifier renders s as a delimited SQL identifier, so it is read as a name
+// whatever characters it contains
+func QuoteIdentifier(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// QuoteQualifiedName renders the parts of a name as a dotted, delimited identifier,
+// e.g. "tpch"."tiny"."nation"
+func QuoteQualifiedName(parts ...string) string {
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		quoted[i] = QuoteIdentifier(part)
+	}
+	return strings.Join(quoted, ".")
+}
+
+// ParseQualifiedName splits a dotted name such as catalog.schema.table into at most
+// maxParts identifiers. Parts in double quotes are taken literally and may contain
+// dots and doubled quotes; unquoted parts are lower-cased, as Trino does.
+func ParseQualifiedName(name string, maxParts int) ([]string, error) {
+	if !utf8.ValidString(name) {
+		return nil, fmt.Errorf("invalid name %q: not valid UTF-8", name)
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return nil, fmt.Errorf("invalid name %q: contains a control character", name)
+	}
+
+	var parts []string
+	i := 0
+	for {
+		for i < len(name) && name[i] == ' ' {
+			i++
+		}
+		var part string
+		if i < len(name) && name[i] == '"' {
+			end, err := scanQuoted(name, i, '"')
+			if err != nil {
+				return nil, fmt.Errorf("invalid name %q: unterminated quoted identifier", name)
+			}
+			part = strings.ReplaceAll(name[i+1:end-1], `""`, `"`)
+			if part == "" {
+				return nil, fmt.Errorf("invalid name %q: empty quoted identifier", name)
+			}
+			i = end
+			for i < len(name) && name[i] == ' ' {
+				i++
+			}
+		} else {
+			start := i
+			for i < len(name) && name[i] != '.' && name[i] != '"' {
+				i++
+			}
+			if i < len(name) && name[i] == '"' {
+				return nil, fmt.Errorf("invalid name %q: unexpected quote in unquoted identifier", name)
+			}
+			part = strings.ToLower(strings.TrimSpace(name[start:i]))
+			if part == "" {
+				return nil, fmt.Errorf("invalid name %q: empty identifier", name)
+			}
+		}
+		parts = append(parts, part)
+
+		if i == len(name) {
+			break
+		}
+		if name[i] != '.' {
+			return nil, fmt.Errorf("invalid name %q: unexpected %q after quoted identifier", name, name[i])
+		}
+		i++
+	}
+
+	if len(parts) > maxParts {
+		return nil, fmt.Errorf("invalid name %q: has %d parts, at most %d allowed", name, len(parts), maxParts)
+	}
+	return parts, nil
+}
+
+// parseIdentifier parses a name that must be a single identifier, e.g. a catalog
+func parseIdentifier(name string) (string, error) {
+	parts, err := ParseQualifiedName(name, 1)
+	if err != nil {
+		return "", err
+	}
+	return parts[0], nil
+}
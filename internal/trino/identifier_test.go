@@ -0,0 +1,116 @@
+package trino
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"nation", `"nation"`},
+		{"My-Schema", `"My-Schema"`},
+		{`say "hi"`, `"say ""hi"""`},
+		{"a.b", `"a.b"`},
+		{"tpch; DROP TABLE x", `"tpch; DROP TABLE x"`},
+	}
+	for _, tt := range tests {
+		if got := QuoteIdentifier(tt.name); got != tt.want {
+			t.Errorf("QuoteIdentifier(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+	if got, want := QuoteQualifiedName("tpch", "tiny", "nation"), `"tpch"."tiny"."nation"`; got != want {
+		t.Errorf("QuoteQualifiedName() = %s, want %s", got, want)
+	}
+}
+
+func TestParseQualifiedName(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxParts int
+		want     []string
+		wantErr  bool
+	}{
+		{name: "nation", maxParts: 1, want: []string{"nation"}},
+		{name: "TPCH.Tiny.Nation", maxParts: 3, want: []string{"tpch", "tiny", "nation"}},
+		{name: " tpch . tiny ", maxParts: 2, want: []string{"tpch", "tiny"}},
+		{name: "my-schema", maxParts: 1, want: []string{"my-schema"}},
+		{name: `"My.Schema".orders`, maxParts: 2, want: []string{"My.Schema", "orders"}},
+		{name: `"say ""hi"""`, maxParts: 1, want: []string{`say "hi"`}},
+		{name: `tpch."Tiny" . "a b"`, maxParts: 3, want: []string{"tpch", "Tiny", "a b"}},
+		{name: "", maxParts: 1, wantErr: true},
+		{name: "a..b", maxParts: 3, wantErr: true},
+		{name: "a.", maxParts: 2, wantErr: true},
+		{name: ".a", maxParts: 2, wantErr: true},
+		{name: `""`, maxParts: 1, wantErr: true},
+		{name: `"unterminated`, maxParts: 1, wantErr: true},
+		{name: `a"b"`, maxParts: 1, wantErr: true},
+		{name: `"a"b`, maxParts: 1, wantErr: true},
+		{name: "a\nb", maxParts: 1, wantErr: true},
+		{name: "a\xffb", maxParts: 1, wantErr: true},
+		{name: "tpch.tiny", maxParts: 1, wantErr: true},
+		{name: "a.b.c.d", maxParts: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseQualifiedName(tt.name, tt.maxParts)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseQualifiedName(%q, %d) error = %v, wantErr %v", tt.name, tt.maxParts, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseQualifiedName(%q, %d) = %q, want %q", tt.name, tt.maxParts, got, tt.want)
+		}
+	}
+}
+
+func FuzzQuoteIdentifier(f *testing.F) {
+	for _, s := range []string{"nation", `a"b`, `""`, "a.b", "x; DROP TABLE y --", "'", "/*", "ünïcode"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		// A quoted identifier is a single token that reads back as s
+		tokens, err := lexSQL(QuoteIdentifier(s))
+		if err != nil {
+			t.Fatalf("lexSQL(%s) error = %v", QuoteIdentifier(s), err)
+		}
+		if len(tokens) != 1 || tokens[0].kind != tokenQuotedIdentifier {
+			t.Fatalf("lexSQL(%s) = %v, want one quoted identifier", QuoteIdentifier(s), tokens)
+		}
+		if got, _ := tokens[0].identifier(); got != s {
+			t.Fatalf("identifier of %s = %q, want %q", QuoteIdentifier(s), got, s)
+		}
+	})
+}
+
+func FuzzParseQualifiedName(f *testing.F) {
+	for _, s := range []string{"tpch.tiny.nation", `"a.b".c`, `"x""y"`, "a..b", `a"b`, " A . B ", "tpch; DROP TABLE x"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		parts, err := ParseQualifiedName(name, 3)
+		if err != nil {
+			return
+		}
+		if len(parts) == 0 || len(parts) > 3 {
+			t.Fatalf("ParseQualifiedName(%q) = %q, want 1 to 3 parts", name, parts)
+		}
+		// Quoting the parts round-trips to the same name
+		quoted := QuoteQualifiedName(parts...)
+		again, err := ParseQualifiedName(quoted, 3)
+		if err != nil || !reflect.DeepEqual(again, parts) {
+			t.Fatalf("ParseQualifiedName(%s) = %q, %v; want %q", quoted, again, err, parts)
+		}
+		// and the quoted name cannot break out of the statement it is used in
+		query := "SHOW TABLES FROM " + quoted
+		stmt, err := ClassifyStatement(query)
+		if err != nil || stmt.Kind != StatementQuery || stmt.Verb != "SHOW" {
+			t.Fatalf("ClassifyStatement(%q) = %+v, %v; want a single SHOW query", query, stmt, err)
+		}
+		tokens, _ := lexSQL(query)
+		if want := 3 + 2*len(parts) - 1; len(tokens) != want {
+			t.Fatalf("lexSQL(%q) = %d tokens, want %d", query, len(tokens), want)
+		}
+	})
+}
@@ -0,0 +1,275 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrJobNotFound is returned when a job ID is unknown or the job has expired
+	ErrJobNotFound = errors.New("job not found or expired; submit the query again")
+	// ErrJobQueueFull is returned when too many submitted jobs are waiting for a worker
+	ErrJobQueueFull = errors.New("too many queued jobs; try again later")
+)
+
+// JobState is the lifecycle state of a background job
+type JobState string
+
+const (
+	JobQueued    JobState = "queued"
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+)
+
+// JobStatus describes a background job. Result holds the first page of the result once
+// the job has succeeded; further pages are fetched with FetchResults.
+type JobStatus struct {
+	JobID              string      `json:"job_id"`
+	State              JobState    `json:"state"`
+	Query              string      `json:"query"`
+	QueryID            string      `json:"query_id,omitempty"`
+	ProgressPercentage float64     `json:"progress_percentage"`
+	ProcessedRows      int64       `json:"processed_rows"`
+	ProcessedBytes     int64       `json:"processed_bytes"`
+	ElapsedMillis      int64       `json:"elapsed_ms"`
+	Error              string      `json:"error,omitempty"`
+	Result             *ResultPage `json:"result,omitempty"`
+	// ResultExpired is set once the rows after the first page are no longer buffered,
+	// because they were read completely, evicted or unused for the job TTL
+	ResultExpired bool `json:"result_expired,omitempty"`
+}
+
+// job is a query submitted for background execution
+type job struct {
+	id       string
+	owner    string
+	query    string
+	pageSize int
+
+	mu       sync.Mutex
+	state    JobState
+	started  time.Time
+	finished time.Time
+	running  *runningQuery
+	result   *ResultPage
+	err      error
+}
+
+// status describes the job, with the progress the driver last reported for its query
+func (j *job) status() *JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	s := &JobStatus{JobID: j.id, State: j.state, Query: j.query, Result: j.result}
+	if j.running != nil {
+		info := j.running.snapshot()
+		s.QueryID = info.QueryID
+		s.ProgressPercentage = info.ProgressPercentage
+		s.ProcessedRows = info.ProcessedRows
+		s.ProcessedBytes = info.ProcessedBytes
+	}
+	switch j.state {
+	case JobRunning:
+		s.ElapsedMillis = time.Since(j.started).Milliseconds()
+	case JobSucceeded, JobFailed:
+		if !j.started.IsZero() {
+			s.ElapsedMillis = j.finished.Sub(j.started).Milliseconds()
+		}
+	}
+	if j.state == JobSucceeded {
+		s.ProgressPercentage = 100
+	}
+	if j.err != nil {
+		s.Error = j.err.Error()
+	}
+	return s
+}
+
+// jobRunner runs submitted queries on a pool of workers, each with the job timeout.
+// Finished jobs are kept for the TTL so their status and result can be polled.
+type jobRunner struct {
+	client  *Client
+	timeout time.Duration
+	ttl     time.Duration
+	queue   chan *job
+
+	// ctx is cancelled when the runner closes, which cancels the running jobs
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+// newJobRunner starts the workers and a janitor that drops expired jobs
+func newJobRunner(client *Client, workers, queueSize int, timeout, ttl time.Duration) *jobRunner {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &jobRunner{
+		client:  client,
+		timeout: timeout,
+		ttl:     ttl,
+		queue:   make(chan *job, queueSize),
+		ctx:     ctx,
+		cancel:  cancel,
+		jobs:    make(map[string]*job),
+	}
+	for i := 0; i < workers; i++ {
+		r.wg.Add(1)
+		go r.worker()
+	}
+	r.wg.Add(1)
+	go r.janitor()
+	return r
+}
+
+func (r *jobRunner) worker() {
+	defer r.wg.Done()
+	for {
+		select {
+		case j := <-r.queue:
+			r.run(j)
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *jobRunner) janitor() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.ttl / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.mu.Lock()
+			r.expireLocked(time.Now())
+			r.mu.Unlock()
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+// expireLocked removes jobs that finished longer than the TTL ago
+func (r *jobRunner) expireLocked(now time.Time) {
+	for id, j := range r.jobs {
+		j.mu.Lock()
+		expired := !j.finished.IsZero() && now.Sub(j.finished) > r.ttl
+		j.mu.Unlock()
+		if expired {
+			delete(r.jobs, id)
+		}
+	}
+}
+
+// close cancels the running jobs and stops the workers. Queued jobs are dropped.
+func (r *jobRunner) close() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// submit queues a query for its owner
+func (r *jobRunner) submit(query, owner string, pageSize int) (*job, error) {
+	id, err := newHandle()
+	if err != nil {
+		return nil, err
+	}
+	j := &job{id: id, owner: owner, query: query, pageSize: pageSize, state: JobQueued}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ctx.Err() != nil {
+		return nil, ErrShuttingDown
+	}
+	select {
+	case r.queue <- j:
+	default:
+		return nil, ErrJobQueueFull
+	}
+	r.jobs[id] = j
+	return j, nil
+}
+
+// get returns a job of its owner; jobs of other owners are reported as not found so
+// job IDs cannot be probed
+func (r *jobRunner) get(id, owner string) (*job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expireLocked(time.Now())
+	j, ok := r.jobs[id]
+	if !ok || j.owner != owner {
+		return nil, ErrJobNotFound
+	}
+	return j, nil
+}
+
+// run executes a job as its owner and buffers its result for pagination as long as
+// the job is kept
+func (r *jobRunner) run(j *job) {
+	j.mu.Lock()
+	j.state, j.started = JobRunning, time.Now()
+	j.mu.Unlock()
+
+	ctx := r.ctx
+	if j.owner != "" {
+		ctx = WithUser(ctx, j.owner)
+	}
+	c := r.client
+	result, err := c.run(ctx, j.query, c.bufferLimits(), r.timeout, func(q *runningQuery) {
+		j.mu.Lock()
+		j.running = q
+		j.mu.Unlock()
+	})
+	var p *ResultPage
+	if err == nil {
+		p = page(result, 0, c.pageLimits(j.pageSize))
+		if p.NextPageToken != "" {
+			p.Handle, err = c.results.putFor(result, j.owner, r.ttl)
+		}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.finished = time.Now()
+	if err != nil {
+		j.state, j.err = JobFailed, err
+		return
+	}
+	j.state, j.result = JobSucceeded, p
+}
+
+// SubmitQuery checks a query against the policy and cost limits and queues it for background execution
+// with the job timeout. The returned job ID is polled with QueryJob; jobs are only
+// visible to the user that submitted them.
+func (c *Client) SubmitQuery(ctx context.Context, query string, pageSize int) (*JobStatus, error) {
+	if err := c.checkPolicy(query); err != nil {
+		return nil, err
+	}
+	if err := c.preflight(ctx, query); err != nil {
+		return nil, err
+	}
+	j, err := c.jobs.submit(query, userFromContext(ctx), pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return j.status(), nil
+}
+
+// QueryJob reports the state and progress of a job and, once it has succeeded, the
+// first page of its result. The handle of the rest is dropped once it is no longer buffered.
+func (c *Client) QueryJob(ctx context.Context, id string) (*JobStatus, error) {
+	j, err := c.jobs.get(id, userFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	s := j.status()
+	if s.Result != nil && s.Result.Handle != "" && !c.results.has(s.Result.Handle) {
+		p := *s.Result
+		p.Handle, p.NextPageToken = "", ""
+		s.Result, s.ResultExpired = &p, true
+	}
+	return s, nil
+}
@@ -0,0 +1,177 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+// waitForJob polls a job until it leaves the queued and running states
+func waitForJob(t *testing.T, client *Client, ctx context.Context, id string) *JobStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status, err := client.QueryJob(ctx, id)
+		if err != nil {
+			t.Fatalf("QueryJob() error = %v", err)
+		}
+		if status.State != JobQueued && status.State != JobRunning {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s still %s", id, status.State)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSubmitQuery(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	srv.SetResult("SELECT id FROM users", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "id", Type: "bigint"}},
+		Rows:    [][]interface{}{{1}, {2}, {3}},
+	})
+	srv.SetResult("SELECT * FROM missing", trinotest.Result{Error: "Table 'missing' does not exist"})
+
+	client, err := NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	alice := WithUser(context.Background(), "alice")
+	submitted, err := client.SubmitQuery(alice, "SELECT id FROM users", 2)
+	if err != nil {
+		t.Fatalf("SubmitQuery() error = %v", err)
+	}
+	if submitted.JobID == "" || submitted.State != JobQueued {
+		t.Errorf("SubmitQuery() = %+v, want a queued job", submitted)
+	}
+
+	status := waitForJob(t, client, alice, submitted.JobID)
+	if status.State != JobSucceeded || status.ProgressPercentage != 100 || status.Result == nil {
+		t.Fatalf("QueryJob() = %+v, want a succeeded job with a result", status)
+	}
+	if status.Result.RowCount != 2 || status.Result.TotalRows != 3 || status.Result.Handle == "" {
+		t.Errorf("job result = %d of %d rows, handle %q; want the first 2 of 3 rows with a handle",
+			status.Result.RowCount, status.Result.TotalRows, status.Result.Handle)
+	}
+	rest, err := client.FetchResults(alice, status.Result.Handle, status.Result.NextPageToken, 2)
+	if err != nil || rest.RowCount != 1 {
+		t.Errorf("FetchResults() = %+v, %v; want the last row", rest, err)
+	}
+
+	// The handle is dropped once the rest of the result has been read
+	status, err = client.QueryJob(alice, submitted.JobID)
+	if err != nil || !status.ResultExpired || status.Result.Handle != "" || status.Result.RowCount != 2 {
+		t.Errorf("QueryJob() after reading the result = %+v, %v; want the first page without a handle", status, err)
+	}
+
+	// Jobs are private to the submitting user
+	if _, err := client.QueryJob(WithUser(context.Background(), "bob"), submitted.JobID); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("QueryJob() by another user error = %v, want ErrJobNotFound", err)
+	}
+	if _, err := client.QueryJob(alice, "unknown"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("QueryJob() of an unknown job error = %v, want ErrJobNotFound", err)
+	}
+
+	failed, err := client.SubmitQuery(alice, "SELECT * FROM missing", 0)
+	if err != nil {
+		t.Fatalf("SubmitQuery() error = %v", err)
+	}
+	status = waitForJob(t, client, alice, failed.JobID)
+	if status.State != JobFailed || !strings.Contains(status.Error, "does not exist") {
+		t.Errorf("QueryJob() = %+v, want a failed job with the Trino error", status)
+	}
+
+	// The policy is checked before the job is queued
+	var violation *PolicyViolationError
+	if _, err := client.SubmitQuery(alice, "DROP TABLE users", 0); !errors.As(err, &violation) {
+		t.Errorf("SubmitQuery() of a write error = %v, want a policy violation", err)
+	}
+}
+
+func TestJobResultKeptForJobTTL(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	srv.SetResult("SELECT id FROM users", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "id", Type: "bigint"}},
+		Rows:    [][]interface{}{{1}, {2}, {3}},
+	})
+
+	cfg := srv.Config()
+	cfg.ResultTTL = 20 * time.Millisecond
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	submitted, err := client.SubmitQuery(ctx, "SELECT id FROM users", 1)
+	if err != nil {
+		t.Fatalf("SubmitQuery() error = %v", err)
+	}
+	status := waitForJob(t, client, ctx, submitted.JobID)
+
+	// A job's result outlives the TTL of execute_query results
+	time.Sleep(60 * time.Millisecond)
+	if status, err = client.QueryJob(ctx, submitted.JobID); err != nil || status.ResultExpired {
+		t.Fatalf("QueryJob() = %+v, %v; want the result still buffered", status, err)
+	}
+	if _, err := client.FetchResults(ctx, status.Result.Handle, status.Result.NextPageToken, 1); err != nil {
+		t.Errorf("FetchResults() after the result TTL error = %v", err)
+	}
+}
+
+func TestSubmitQueryTimeoutAndQueueLimit(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	srv.SetResult("SELECT * FROM huge", trinotest.Result{Block: true})
+
+	cfg := srv.Config()
+	cfg.JobTimeout = 200 * time.Millisecond
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	submitted, err := client.SubmitQuery(ctx, "SELECT * FROM huge", 0)
+	if err != nil {
+		t.Fatalf("SubmitQuery() error = %v", err)
+	}
+	status := waitForJob(t, client, ctx, submitted.JobID)
+	if status.State != JobFailed || status.Error == "" {
+		t.Errorf("QueryJob() = %+v, want a job failed by its timeout", status)
+	}
+	if status.QueryID == "" {
+		t.Errorf("QueryJob() = %+v, want the Trino query ID", status)
+	}
+
+	// Two workers and a queue of four accept at most six blocking jobs
+	cfg = srv.Config()
+	blocked, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer blocked.Close()
+	for i := 0; ; i++ {
+		_, err := blocked.SubmitQuery(ctx, "SELECT * FROM huge", 0)
+		if errors.Is(err, ErrJobQueueFull) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("SubmitQuery() error = %v", err)
+		}
+		if i >= cfg.JobWorkers+cfg.JobQueueSize {
+			t.Fatalf("SubmitQuery() accepted %d jobs, want ErrJobQueueFull", i+1)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package trino
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MetadataCacheStats counts the lookups of a cluster's metadata cache
+type MetadataCacheStats struct {
+	Enabled bool `json:"enabled"`
+	Entries int  `json:"entries"`
+	Hits    int  `json:"hits"`
+	// Misses counts lookups that were not answered from the cache; Shared of them waited
+	// for a concurrent lookup of the same listing instead of querying Trino again
+	Misses    int `json:"misses"`
+	Shared    int `json:"shared"`
+	Evictions int `json:"evictions"`
+}
+
+// MetadataRefresh reports the cache entries a refresh invalidated on a cluster
+type MetadataRefresh struct {
+	Cluster     string             `json:"cluster"`
+	Invalidated int                `json:"invalidated"`
+	Cache       MetadataCacheStats `json:"cache"`
+}
+
+// Kinds of cached metadata listings
+const (
+	metadataCatalogs = "catalogs"
+	metadataSchemas  = "schemas"
+	metadataTables   = "tables"
+	metadataTable    = "table"
+)
+
+// metadataKey identifies a cached listing. Listings are cached per user, as access
+// control may show each user different catalogs, schemas and tables.
+type metadataKey struct {
+	user    string
+	kind    string
+	catalog string
+	schema  string
+	table   string
+}
+
+// under reports whether the listing is on the path to, or below, the named object.
+// Empty names match anything, so the catalog listing is under every catalog.
+func (k metadataKey) under(catalog, schema, table string) bool {
+	match := func(name, filter string) bool {
+		return name == "" || filter == "" || name == filter
+	}
+	return match(k.catalog, catalog) && match(k.schema, schema) && match(k.table, table)
+}
+
+type metadataEntry struct {
+	value    interface{}
+	expires  time.Time
+	lastUsed time.Time
+}
+
+// metadataCall is a lookup in progress, which concurrent misses of the same key wait for
+type metadataCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+	// abandoned is set when the lookup failed because its caller went away, which says
+	// nothing about the lookups of the callers waiting for it
+	abandoned bool
+}
+
+// metadataCache caches metadata listings for a TTL. It is bounded by the number of
+// listings it keeps, evicting the least recently used. Cached values are shared by
+// every caller and must not be modified. A nil cache caches nothing.
+type metadataCache struct {
+	ttl  time.Duration
+	size int
+
+	mu      sync.Mutex
+	entries map[metadataKey]*metadataEntry
+	calls   map[metadataKey]*metadataCall
+	// generation changes on every invalidation, so lookups that started before it do
+	// not cache what may be stale
+	generation int
+	stats      MetadataCacheStats
+}
+
+// newMetadataCache creates a cache, or returns nil when the TTL or size disables it
+func newMetadataCache(ttl time.Duration, size int) *metadataCache {
+	if ttl <= 0 || size <= 0 {
+		return nil
+	}
+	return &metadataCache{
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[metadataKey]*metadataEntry),
+		calls:   make(map[metadataKey]*metadataCall),
+		stats:   MetadataCacheStats{Enabled: true},
+	}
+}
+
+// get returns the cached value of a key, or loads it with the caller's ctx. Concurrent
+// misses of a key share one load and receive its error too, except when the caller
+// that ran it was cancelled: the waiters then look the key up again.
+func (m *metadataCache) get(ctx context.Context, key metadataKey, load func() (interface{}, error)) (interface{}, error) {
+	if m == nil {
+		return load()
+	}
+
+	m.mu.Lock()
+	for {
+		now := time.Now()
+		if entry, ok := m.entries[key]; ok {
+			if now.Before(entry.expires) {
+				entry.lastUsed = now
+				m.stats.Hits++
+				m.mu.Unlock()
+				return entry.value, nil
+			}
+			delete(m.entries, key)
+		}
+		m.stats.Misses++
+		call, ok := m.calls[key]
+		if !ok {
+			break
+		}
+		m.stats.Shared++
+		m.mu.Unlock()
+		select {
+		case <-call.done:
+			if !call.abandoned {
+				return call.value, call.err
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		m.mu.Lock()
+	}
+	call := &metadataCall{done: make(chan struct{})}
+	m.calls[key] = call
+	generation := m.generation
+	m.mu.Unlock()
+
+	call.value, call.err = load()
+	call.abandoned = call.err != nil && ctx.Err() != nil
+
+	m.mu.Lock()
+	delete(m.calls, key)
+	if call.err == nil && generation == m.generation {
+		m.putLocked(key, call.value, time.Now())
+	}
+	m.mu.Unlock()
+	close(call.done)
+	return call.value, call.err
+}
+
+// putLocked stores a value, making room by dropping expired entries and then the least
+// recently used ones
+func (m *metadataCache) putLocked(key metadataKey, value interface{}, now time.Time) {
+	if len(m.entries) >= m.size {
+		for k, entry := range m.entries {
+			if !now.Before(entry.expires) {
+				delete(m.entries, k)
+			}
+		}
+	}
+	for len(m.entries) >= m.size {
+		var oldest metadataKey
+		var oldestUsed time.Time
+		for k, entry := range m.entries {
+			if oldestUsed.IsZero() || entry.lastUsed.Before(oldestUsed) {
+				oldest, oldestUsed = k, entry.lastUsed
+			}
+		}
+		delete(m.entries, oldest)
+		m.stats.Evictions++
+	}
+	m.entries[key] = &metadataEntry{value: value, expires: now.Add(m.ttl), lastUsed: now}
+}
+
+// invalidate drops the listings on the path to or below the named object, for every
+// user, and returns how many were dropped. Empty names invalidate everything.
+func (m *metadataCache) invalidate(catalog, schema, table string) int {
+	if m == nil {
+		return 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.generation++
+	n := 0
+	for key := range m.entries {
+		if key.under(catalog, schema, table) {
+			delete(m.entries, key)
+			n++
+		}
+	}
+	return n
+}
+
+// statistics returns the lookup counters and the number of cached listings
+func (m *metadataCache) statistics() MetadataCacheStats {
+	if m == nil {
+		return MetadataCacheStats{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := m.stats
+	stats.Entries = len(m.entries)
+	return stats
+}
+
+// cachedMetadata looks up a listing of the caller's user in the client's metadata cache
+func cachedMetadata[T any](ctx context.Context, c *Client, key metadataKey, load func() (T, error)) (T, error) {
+	key.user = userFromContext(ctx)
+	value, err := c.metadata.get(ctx, key, func() (interface{}, error) {
+		return load()
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return value.(T), nil
+}
+
+// RefreshMetadata invalidates the cached listings on the path to or below a catalog,
+// schema or table, for every user. Names default like those of GetTableSchema; with
+// no names the whole cache is invalidated. It returns the number of listings dropped.
+func (c *Client) RefreshMetadata(catalog, schema, table string) (int, error) {
+	switch {
+	case table != "":
+		name, err := c.tableName(catalog, schema, table)
+		if err != nil {
+			return 0, err
+		}
+		return c.metadata.invalidate(name[0], name[1], name[2]), nil
+	case schema != "":
+		catalog, schema, err := c.schemaName(catalog, schema)
+		if err != nil {
+			return 0, err
+		}
+		return c.metadata.invalidate(catalog, schema, ""), nil
+	case catalog != "":
+		catalog, err := c.catalogName(catalog)
+		if err != nil {
+			return 0, err
+		}
+		return c.metadata.invalidate(catalog, "", ""), nil
+	}
+	return c.metadata.invalidate("", "", ""), nil
+}
+
+// MetadataCacheStats returns the counters of the client's metadata cache
+func (c *Client) MetadataCacheStats() MetadataCacheStats {
+	return c.metadata.statistics()
+}
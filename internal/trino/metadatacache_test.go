@@ -0,0 +1,242 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+// statements counts the statements with the given SQL the server received
+func statements(srv *trinotest.Server, sql string) int {
+	n := 0
+	for _, r := range srv.Requests() {
+		if r.SQL == sql {
+			n++
+		}
+	}
+	return n
+}
+
+func TestMetadataCache(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	varchar := func(name string) []trinotest.Column {
+		return []trinotest.Column{{Name: name, Type: "varchar"}}
+	}
+	srv.SetResult("SHOW CATALOGS", trinotest.Result{Columns: varchar("Catalog"), Rows: [][]interface{}{{"tpch"}}})
+	srv.SetResult(`SHOW SCHEMAS FROM "tpch"`, trinotest.Result{
+		Columns: varchar("Schema"),
+		Rows:    [][]interface{}{{"tiny"}},
+		Delay:   300 * time.Millisecond,
+	})
+	srv.SetResult(`SHOW TABLES FROM "tpch"."tiny"`, trinotest.Result{Columns: varchar("Table"), Rows: [][]interface{}{{"nation"}}})
+	srv.SetResult(`SHOW TABLES FROM "tpch"."sf1"`, trinotest.Result{Columns: varchar("Table"), Rows: [][]interface{}{{"nation"}}})
+
+	cfg := srv.Config()
+	cfg.MetadataCacheTTL = time.Minute
+	cfg.MetadataCacheSize = 100
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if catalogs, err := client.ListCatalogs(ctx); err != nil || len(catalogs) != 1 || catalogs[0] != "tpch" {
+			t.Fatalf("ListCatalogs() = %v, %v", catalogs, err)
+		}
+	}
+	if n := statements(srv, "SHOW CATALOGS"); n != 1 {
+		t.Errorf("SHOW CATALOGS ran %d times, want 1", n)
+	}
+	// Listings are cached per user
+	if _, err := client.ListCatalogs(WithUser(ctx, "alice")); err != nil {
+		t.Fatalf("ListCatalogs(alice) error = %v", err)
+	}
+	if n := statements(srv, "SHOW CATALOGS"); n != 2 {
+		t.Errorf("SHOW CATALOGS ran %d times, want 2 after another user's lookup", n)
+	}
+	if stats := client.MetadataCacheStats(); !stats.Enabled || stats.Hits != 2 || stats.Misses != 2 || stats.Entries != 2 {
+		t.Errorf("MetadataCacheStats() = %+v, want 2 hits, 2 misses and 2 entries", stats)
+	}
+
+	// Concurrent misses share one lookup
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if schemas, err := client.ListSchemas(ctx, "tpch"); err != nil || len(schemas) != 1 {
+				t.Errorf("ListSchemas() = %v, %v", schemas, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := statements(srv, `SHOW SCHEMAS FROM "tpch"`); n != 1 {
+		t.Errorf("SHOW SCHEMAS ran %d times for concurrent lookups, want 1", n)
+	}
+	if stats := client.MetadataCacheStats(); stats.Shared != 4 {
+		t.Errorf("MetadataCacheStats().Shared = %d, want 4", stats.Shared)
+	}
+
+	// Refreshing a schema drops the listings on its path and below, but not its siblings
+	for _, schema := range []string{"tiny", "sf1"} {
+		if _, err := client.ListTables(ctx, "tpch", schema); err != nil {
+			t.Fatalf("ListTables(%s) error = %v", schema, err)
+		}
+	}
+	n, err := client.RefreshMetadata("TPCH", "tiny", "")
+	if err != nil {
+		t.Fatalf("RefreshMetadata() error = %v", err)
+	}
+	// Both users' catalog listings, the schema listing and the tiny table listing
+	if n != 4 {
+		t.Errorf("RefreshMetadata() invalidated %d listings, want 4", n)
+	}
+	for _, schema := range []string{"tiny", "sf1"} {
+		if _, err := client.ListTables(ctx, "tpch", schema); err != nil {
+			t.Fatalf("ListTables(%s) error = %v", schema, err)
+		}
+	}
+	if tiny, sf1 := statements(srv, `SHOW TABLES FROM "tpch"."tiny"`), statements(srv, `SHOW TABLES FROM "tpch"."sf1"`); tiny != 2 || sf1 != 1 {
+		t.Errorf("SHOW TABLES ran %d times for tiny and %d for sf1, want 2 and 1", tiny, sf1)
+	}
+
+	// Failed lookups are not cached
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetTableSchema(ctx, "tpch", "tiny", "missing"); err == nil {
+			t.Fatal("GetTableSchema(missing) succeeded, want error")
+		}
+	}
+	if n := statements(srv, `DESCRIBE "tpch"."tiny"."missing"`); n != 2 {
+		t.Errorf("DESCRIBE of a missing table ran %d times, want 2", n)
+	}
+
+	if _, err := client.RefreshMetadata("", "", "a.b.c.d"); err == nil {
+		t.Error("RefreshMetadata() of an invalid name succeeded, want error")
+	}
+	entries := client.MetadataCacheStats().Entries
+	if n, err := client.RefreshMetadata("", "", ""); err != nil || n != entries {
+		t.Errorf("RefreshMetadata() = %d, %v; want all %d listings invalidated", n, err, entries)
+	}
+	if entries := client.MetadataCacheStats().Entries; entries != 0 {
+		t.Errorf("MetadataCacheStats().Entries = %d after refreshing everything, want 0", entries)
+	}
+}
+
+func TestMetadataCacheBounds(t *testing.T) {
+	ctx := context.Background()
+	loads := 0
+	load := func(value string) func() (interface{}, error) {
+		return func() (interface{}, error) {
+			loads++
+			return value, nil
+		}
+	}
+	key := func(catalog string) metadataKey {
+		return metadataKey{kind: metadataSchemas, catalog: catalog}
+	}
+
+	// The least recently used listing is evicted when the cache is full
+	m := newMetadataCache(time.Minute, 2)
+	for _, catalog := range []string{"a", "b", "a", "c", "a", "b"} {
+		if _, err := m.get(ctx, key(catalog), load(catalog)); err != nil {
+			t.Fatalf("get(%s) error = %v", catalog, err)
+		}
+	}
+	// a, b and c miss; a hits twice; b was evicted by c and misses again
+	if stats := m.statistics(); loads != 4 || stats.Hits != 2 || stats.Evictions != 2 || stats.Entries != 2 {
+		t.Errorf("%d loads, stats %+v; want 4 loads, 2 hits, 2 evictions and 2 entries", loads, stats)
+	}
+
+	// Listings expire after the TTL
+	m, loads = newMetadataCache(20*time.Millisecond, 10), 0
+	_, _ = m.get(ctx, key("a"), load("a"))
+	time.Sleep(40 * time.Millisecond)
+	_, _ = m.get(ctx, key("a"), load("a"))
+	if loads != 2 {
+		t.Errorf("%d loads across the TTL, want 2", loads)
+	}
+
+	// A lookup that overlaps an invalidation is not cached, as it may be stale
+	m, loads = newMetadataCache(time.Minute, 10), 0
+	_, _ = m.get(ctx, key("a"), func() (interface{}, error) {
+		loads++
+		m.invalidate("a", "", "")
+		return "stale", nil
+	})
+	if value, _ := m.get(ctx, key("a"), load("fresh")); value != "fresh" || loads != 2 {
+		t.Errorf("get() after an overlapping invalidation = %v with %d loads, want fresh with 2", value, loads)
+	}
+
+	// A disabled cache always loads
+	disabled := newMetadataCache(0, 10)
+	loads = 0
+	for i := 0; i < 2; i++ {
+		if _, err := disabled.get(ctx, key("a"), load("a")); err != nil {
+			t.Fatalf("get() error = %v", err)
+		}
+	}
+	if loads != 2 || disabled.invalidate("", "", "") != 0 || disabled.statistics().Enabled {
+		t.Errorf("disabled cache: %d loads, want 2 and no statistics", loads)
+	}
+
+	// Errors are returned and not cached
+	m = newMetadataCache(time.Minute, 10)
+	failure := errors.New("unavailable")
+	if _, err := m.get(ctx, key("a"), func() (interface{}, error) { return nil, failure }); !errors.Is(err, failure) {
+		t.Errorf("get() error = %v, want %v", err, failure)
+	}
+	if stats := m.statistics(); stats.Entries != 0 {
+		t.Errorf("failed lookup cached: %+v", stats)
+	}
+}
+
+func TestMetadataCacheCancelledLoad(t *testing.T) {
+	m := newMetadataCache(time.Minute, 10)
+	key := metadataKey{kind: metadataCatalogs}
+
+	// The first caller's lookup runs on its ctx, which it cancels while another caller waits
+	firstCtx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := m.get(firstCtx, key, func() (interface{}, error) {
+			close(started)
+			<-firstCtx.Done()
+			return nil, firstCtx.Err()
+		})
+		firstErr <- err
+	}()
+	<-started
+
+	type lookup struct {
+		value interface{}
+		err   error
+	}
+	waiter := make(chan lookup, 1)
+	go func() {
+		value, err := m.get(context.Background(), key, func() (interface{}, error) { return "tpch", nil })
+		waiter <- lookup{value, err}
+	}()
+	for m.statistics().Shared == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("get() of the cancelled caller error = %v, want context.Canceled", err)
+	}
+	// The waiter is not cancelled with it and looks the listing up itself
+	if got := <-waiter; got.err != nil || got.value != "tpch" {
+		t.Errorf("get() of the waiter = %v, %v; want tpch", got.value, got.err)
+	}
+	if stats := m.statistics(); stats.Entries != 1 {
+		t.Errorf("MetadataCacheStats().Entries = %d after the waiter's lookup, want 1", stats.Entries)
+	}
+}
@@ -0,0 +1,274 @@
+package trino
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy actions
+const (
+	PolicyAllow = "allow"
+	PolicyDeny  = "deny"
+)
+
+// Policy decides which statements may be sent to Trino. Rules are evaluated in
+// order and the first matching rule wins; Default applies when no rule matches.
+type Policy struct {
+	Default string       `yaml:"default" json:"default"`
+	Rules   []PolicyRule `yaml:"rules" json:"rules"`
+}
+
+// PolicyRule matches statements by kind, verb and target. Empty fields match anything,
+// except that a rule with Targets only matches statements that have a target.
+type PolicyRule struct {
+	Name   string          `yaml:"name" json:"name"`
+	Action string          `yaml:"action" json:"action"`
+	Kinds  []StatementKind `yaml:"kinds" json:"kinds"`
+	// Verbs are matched case-insensitively against Statement.Verb, e.g. "CREATE TABLE"
+	Verbs []string `yaml:"verbs" json:"verbs"`
+	// Targets are dotted glob patterns. Three-part patterns (catalog.schema.table) match
+	// tables, views and procedures; two-part patterns (catalog.schema) match schemas.
+	// Like Trino's identifiers, they are matched case-insensitively.
+	Targets []string `yaml:"targets" json:"targets"`
+	// Reason is reported to the caller when the rule denies a statement
+	Reason string `yaml:"reason" json:"reason"`
+}
+
+// PolicyViolationError is returned when the policy refuses a statement
+type PolicyViolationError struct {
+	Kind   StatementKind `json:"kind"`
+	Verb   string        `json:"verb,omitempty"`
+	Target string        `json:"target,omitempty"`
+	Rule   string        `json:"rule,omitempty"`
+	Reason string        `json:"reason"`
+}
+
+// Error implements the error interface
+func (e *PolicyViolationError) Error() string {
+	msg := fmt.Sprintf("policy violation: %s statement", e.Kind)
+	if e.Verb != "" {
+		msg += fmt.Sprintf(" (%s)", e.Verb)
+	}
+	if e.Target != "" {
+		msg += " on " + e.Target
+	}
+	if e.Rule != "" {
+		msg += fmt.Sprintf(" denied by rule %q", e.Rule)
+	} else {
+		msg += " denied by default"
+	}
+	return msg + ": " + e.Reason
+}
+
+const readOnlyReason = "only SELECT, SHOW, DESCRIBE, and EXPLAIN queries are allowed. " +
+	"Set TRINO_ALLOW_WRITE_QUERIES=true to enable write operations (at your own risk)"
+
+// DefaultPolicy returns the policy used when no policy file is configured:
+// read-only statements only, or everything when allowWrites is set
+func DefaultPolicy(allowWrites bool) *Policy {
+	if allowWrites {
+		return &Policy{Default: PolicyAllow}
+	}
+	return &Policy{
+		Default: PolicyDeny,
+		Rules: []PolicyRule{
+			{Name: "read-only", Action: PolicyAllow, Kinds: []StatementKind{StatementQuery, StatementExplainAnalyze}},
+			{Name: "read-only", Action: PolicyDeny, Reason: readOnlyReason},
+		},
+	}
+}
+
+// LoadPolicy reads a policy from a YAML or JSON file
+func LoadPolicy(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var p Policy
+	// YAML is a superset of JSON, so one decoder handles both formats
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file %s: %w", file, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", file, err)
+	}
+	return &p, nil
+}
+
+// validate normalizes the policy and rejects unknown actions, kinds and malformed patterns
+func (p *Policy) validate() error {
+	if p.Default == "" {
+		p.Default = PolicyDeny
+	}
+	if err := validateAction(p.Default); err != nil {
+		return fmt.Errorf("default: %w", err)
+	}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if err := validateAction(r.Action); err != nil {
+			return fmt.Errorf("%s: %w", r.Name, err)
+		}
+		for _, kind := range r.Kinds {
+			switch kind {
+			case StatementQuery, StatementDDL, StatementDML, StatementSession,
+				StatementProcedure, StatementExplainAnalyze, StatementUnknown:
+			default:
+				return fmt.Errorf("%s: unknown statement kind %q", r.Name, kind)
+			}
+		}
+		for j, pattern := range r.Targets {
+			// Trino folds identifiers to lower case, quoted or not
+			pattern = strings.ToLower(pattern)
+			r.Targets[j] = pattern
+			parts := strings.Split(pattern, ".")
+			if len(parts) < 2 || len(parts) > 3 {
+				return fmt.Errorf("%s: target %q must be catalog.schema or catalog.schema.table", r.Name, pattern)
+			}
+			for _, part := range parts {
+				if _, err := path.Match(part, ""); err != nil {
+					return fmt.Errorf("%s: target %q: %w", r.Name, pattern, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func validateAction(action string) error {
+	if action != PolicyAllow && action != PolicyDeny {
+		return fmt.Errorf("action must be %q or %q, got %q", PolicyAllow, PolicyDeny, action)
+	}
+	return nil
+}
+
+// Check returns a *PolicyViolationError if the statement is not allowed. Relative
+// targets are resolved against the given default catalog and schema. For EXPLAIN ANALYZE
+// both the EXPLAIN itself and the statement it runs must be allowed; for a rename, the
+// statement must be allowed on both the old and the new name.
+func (p *Policy) Check(stmt Statement, catalog, schema string) error {
+	if err := p.check(stmt, catalog, schema); err != nil {
+		return err
+	}
+	if stmt.Destination != nil {
+		renamed := stmt
+		renamed.Target = stmt.Destination
+		if err := p.check(renamed, catalog, schema); err != nil {
+			return err
+		}
+	}
+	if stmt.Inner != nil {
+		return p.check(*stmt.Inner, catalog, schema)
+	}
+	return nil
+}
+
+func (p *Policy) check(stmt Statement, catalog, schema string) error {
+	target := resolveTarget(stmt, catalog, schema)
+	for _, rule := range p.Rules {
+		if !rule.matches(stmt, target) {
+			continue
+		}
+		if rule.Action == PolicyAllow {
+			return nil
+		}
+		return violation(stmt, target, rule.Name, rule.Reason)
+	}
+	if p.Default == PolicyAllow {
+		return nil
+	}
+	return violation(stmt, target, "", "no policy rule allows this statement")
+}
+
+func violation(stmt Statement, target []string, rule, reason string) error {
+	if reason == "" {
+		reason = "statement is not permitted by the configured policy"
+	}
+	return &PolicyViolationError{
+		Kind:   stmt.Kind,
+		Verb:   stmt.Verb,
+		Target: strings.Join(target, "."),
+		Rule:   rule,
+		Reason: reason,
+	}
+}
+
+func (r PolicyRule) matches(stmt Statement, target []string) bool {
+	if len(r.Kinds) > 0 && !containsKind(r.Kinds, stmt.Kind) {
+		return false
+	}
+	if len(r.Verbs) > 0 && !containsFold(r.Verbs, stmt.Verb) {
+		return false
+	}
+	if len(r.Targets) > 0 {
+		if target == nil {
+			return false
+		}
+		for _, pattern := range r.Targets {
+			if matchTarget(pattern, target) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// resolveTarget expands a statement target to catalog.schema[.name], in lower case as
+// Trino folds the case of quoted identifiers too
+func resolveTarget(stmt Statement, catalog, schema string) []string {
+	if len(stmt.Target) == 0 {
+		return nil
+	}
+	want := 3
+	if stmt.TargetType == targetSchema {
+		want = 2
+	}
+	defaults := []string{catalog, schema}
+	resolved := append([]string{}, stmt.Target...)
+	for len(resolved) < want {
+		resolved = append([]string{defaults[want-len(resolved)-1]}, resolved...)
+	}
+	for i, part := range resolved {
+		resolved[i] = strings.ToLower(part)
+	}
+	return resolved
+}
+
+func matchTarget(pattern string, target []string) bool {
+	parts := strings.Split(pattern, ".")
+	if len(parts) != len(target) {
+		return false
+	}
+	for i, part := range parts {
+		if ok, _ := path.Match(part, target[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func containsKind(kinds []StatementKind, kind StatementKind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package trino
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const analystPolicy = `
+default: deny
+rules:
+  - name: read-anything
+    kinds: [query, explain_analyze]
+    action: allow
+  - name: scratch-writes
+    kinds: [ddl, dml]
+    verbs: ["CREATE TABLE", "INSERT", "DROP TABLE", "ALTER TABLE", "ALTER SCHEMA"]
+    targets: ["hive.scratch.*", "hive.scratch"]
+    action: allow
+  - name: no-destruction
+    kinds: [ddl, dml]
+    verbs: ["DROP TABLE", "DROP SCHEMA", "DELETE", "TRUNCATE"]
+    action: deny
+    reason: destructive statements are only allowed in hive.scratch
+`
+
+func TestPolicyCheck(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(file, []byte(analystPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := LoadPolicy(file)
+	if err != nil {
+		t.Fatalf("LoadPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		allowed bool
+		rule    string // denying rule, empty for the default
+	}{
+		{"Query", "SELECT * FROM hive.sales.orders", true, ""},
+		{"CTAS into scratch", "CREATE TABLE hive.scratch.tmp AS SELECT 1 AS x", true, ""},
+		{"CTAS into scratch via default schema", "CREATE TABLE tmp AS SELECT 1 AS x", true, ""},
+		{"Drop in scratch", "DROP TABLE IF EXISTS hive.scratch.tmp", true, ""},
+		{"Drop elsewhere", "DROP TABLE hive.sales.orders", false, "no-destruction"},
+		{"Delete in scratch", "DELETE FROM hive.scratch.tmp", false, "no-destruction"},
+		{"CTAS elsewhere", "CREATE TABLE hive.sales.copy AS SELECT 1 AS x", false, ""},
+		{"Explain analyze write", "EXPLAIN ANALYZE DELETE FROM hive.sales.orders", false, "no-destruction"},
+		{"Call", "CALL system.runtime.kill_query(query_id => 'x')", false, ""},
+		{"Rename within scratch", "ALTER TABLE hive.scratch.tmp RENAME TO hive.scratch.tmp2", true, ""},
+		{"Rename out of scratch", "ALTER TABLE hive.scratch.tmp RENAME TO hive.sales.orders", false, ""},
+		{"Rename into scratch", "ALTER TABLE hive.sales.orders RENAME TO tmp", false, ""},
+		{"Rename scratch schema", "ALTER SCHEMA hive.scratch RENAME TO sales", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := ClassifyStatement(tt.query)
+			if err != nil {
+				t.Fatalf("ClassifyStatement(%q) error = %v", tt.query, err)
+			}
+			err = policy.Check(stmt, "hive", "scratch")
+			if tt.allowed {
+				if err != nil {
+					t.Errorf("Check(%q) = %v, want allowed", tt.query, err)
+				}
+				return
+			}
+			var violation *PolicyViolationError
+			if !errors.As(err, &violation) {
+				t.Fatalf("Check(%q) = %v, want policy violation", tt.query, err)
+			}
+			if violation.Rule != tt.rule {
+				t.Errorf("Check(%q) denied by rule %q, want %q", tt.query, violation.Rule, tt.rule)
+			}
+		})
+	}
+}
+
+func TestPolicyTargetCase(t *testing.T) {
+	policy := &Policy{
+		Default: PolicyAllow,
+		Rules: []PolicyRule{
+			{Name: "protect-prod", Action: PolicyDeny, Kinds: []StatementKind{StatementDDL, StatementDML}, Targets: []string{"hive.PROD.*"}},
+		},
+	}
+	if err := policy.validate(); err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+
+	tests := []struct {
+		query   string
+		allowed bool
+	}{
+		{"DROP TABLE hive.prod.orders", false},
+		{`DROP TABLE hive."PROD".orders`, false},
+		{`DROP TABLE "Hive"."Prod"."Orders"`, false},
+		{"DELETE FROM HIVE.Prod.Orders", false},
+		{`INSERT INTO "orders" VALUES (1)`, false},
+		{`DROP TABLE hive."PROD_OLD".orders`, true},
+		{"DROP TABLE hive.scratch.orders", true},
+	}
+	for _, tt := range tests {
+		stmt, err := ClassifyStatement(tt.query)
+		if err != nil {
+			t.Fatalf("ClassifyStatement(%q) error = %v", tt.query, err)
+		}
+		// Relative names resolve against a default schema written in upper case
+		err = policy.Check(stmt, "hive", "Prod")
+		if tt.allowed && err != nil {
+			t.Errorf("Check(%q) = %v, want allowed", tt.query, err)
+		}
+		var violation *PolicyViolationError
+		if !tt.allowed && (!errors.As(err, &violation) || violation.Rule != "protect-prod") {
+			t.Errorf("Check(%q) = %v, want denied by protect-prod", tt.query, err)
+		}
+	}
+}
+
+func TestDefaultPolicy(t *testing.T) {
+	write, err := ClassifyStatement("INSERT INTO users VALUES (1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := ClassifyStatement("SELECT * FROM users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DefaultPolicy(false).Check(read, "memory", "default"); err != nil {
+		t.Errorf("read-only policy refused a query: %v", err)
+	}
+	if err := DefaultPolicy(false).Check(write, "memory", "default"); err == nil {
+		t.Error("read-only policy allowed an INSERT")
+	}
+	if err := DefaultPolicy(true).Check(write, "memory", "default"); err != nil {
+		t.Errorf("write-enabled policy refused an INSERT: %v", err)
+	}
+}
+
+func TestLoadPolicyErrors(t *testing.T) {
+	tests := map[string]string{
+		"Unknown action":  `{"rules": [{"action": "maybe"}]}`,
+		"Unknown kind":    `{"rules": [{"action": "allow", "kinds": ["select"]}]}`,
+		"Bad target":      `{"rules": [{"action": "allow", "targets": ["hive"]}]}`,
+		"Invalid pattern": `{"rules": [{"action": "allow", "targets": ["hive.[.x"]}]}`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "policy.json")
+			if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadPolicy(file); err == nil {
+				t.Errorf("LoadPolicy(%s) succeeded, want error", content)
+			}
+		})
+	}
+}
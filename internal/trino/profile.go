@@ -0,0 +1,396 @@
+package trino
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// defaultProfileSampleRows is how many rows a profile samples by default
+	defaultProfileSampleRows = 1000
+	// maxProfileSampleRows caps the rows a profile samples
+	maxProfileSampleRows = 10000
+	// defaultProfileTopValues is how many of the most frequent values are reported by default
+	defaultProfileTopValues = 5
+	// maxProfileTopValues caps the most frequent values reported per column
+	maxProfileTopValues = 20
+	// maxProfileColumns caps the columns profiled at once
+	maxProfileColumns = 50
+	// profileSampleValues is how many distinct sample values are reported per column
+	profileSampleValues = 5
+	// maxProfileValueLength is the length at which reported string values are cut
+	maxProfileValueLength = 200
+)
+
+// ProfileOptions selects the columns of a profile and bounds its sample. Zero values
+// select the defaults.
+type ProfileOptions struct {
+	// Columns are the columns to profile; all columns by default
+	Columns []string
+	// SamplePercent is the percentage of rows sampled with TABLESAMPLE BERNOULLI. By
+	// default it is derived from the row count statistic, or 100 when there is none.
+	SamplePercent float64
+	// SampleRows caps the sampled rows
+	SampleRows int
+	// TopValues is how many of the most frequent values are reported per column
+	TopValues int
+}
+
+// TableProfile describes the value distribution of a table's columns, from the
+// connector's statistics and from a sample of rows
+type TableProfile struct {
+	Catalog string `json:"catalog"`
+	Schema  string `json:"schema"`
+	Table   string `json:"table"`
+	// RowCount is the row count statistic; nil when the connector has none
+	RowCount      *float64        `json:"row_count"`
+	SamplePercent float64         `json:"sample_percent"`
+	SampledRows   int             `json:"sampled_rows"`
+	Columns       []ColumnProfile `json:"columns"`
+	// Warnings notes missing statistics and columns that were not profiled
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ColumnProfile describes the values of a column. The statistics of SHOW STATS are nil
+// when the connector has none; Sample is computed from the sampled rows.
+type ColumnProfile struct {
+	Name          string       `json:"name"`
+	Type          string       `json:"type"`
+	NullFraction  *float64     `json:"null_fraction"`
+	DistinctCount *float64     `json:"distinct_count"`
+	DataSize      *float64     `json:"data_size,omitempty"`
+	Low           string       `json:"low,omitempty"`
+	High          string       `json:"high,omitempty"`
+	Sample        ColumnSample `json:"sample"`
+}
+
+// ColumnSample describes the values of a column in the sampled rows
+type ColumnSample struct {
+	NullFraction  float64 `json:"null_fraction"`
+	DistinctCount int     `json:"distinct_count"`
+	// Min and Max are omitted for types without a useful order, e.g. maps and booleans
+	Min       interface{}   `json:"min,omitempty"`
+	Max       interface{}   `json:"max,omitempty"`
+	TopValues []ValueCount  `json:"top_values"`
+	Values    []interface{} `json:"values"`
+}
+
+// ValueCount is a value and how often it occurs in the sample
+type ValueCount struct {
+	Value interface{} `json:"value"`
+	Count int         `json:"count"`
+}
+
+// ProfileTable profiles the columns of a table with SHOW STATS and a sample of its rows.
+// The table is resolved like in GetTableSchema. The sample query is subject to the cost
+// preflight, as sampling reads the table.
+func (c *Client) ProfileTable(ctx context.Context, catalog, schema, table string, opts ProfileOptions) (*TableProfile, error) {
+	if opts.SamplePercent < 0 || opts.SamplePercent > 100 {
+		return nil, fmt.Errorf("sample percent must be between 0 and 100, got %g", opts.SamplePercent)
+	}
+	sampleRows := opts.SampleRows
+	if sampleRows <= 0 {
+		sampleRows = defaultProfileSampleRows
+	}
+	sampleRows = min(sampleRows, maxProfileSampleRows)
+	topValues := opts.TopValues
+	if topValues <= 0 {
+		topValues = defaultProfileTopValues
+	}
+	topValues = min(topValues, maxProfileTopValues)
+
+	name, err := c.tableName(catalog, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	quoted := QuoteQualifiedName(name...)
+	description, err := c.GetTableSchema(ctx, "", "", quoted)
+	if err != nil {
+		return nil, err
+	}
+	profile := &TableProfile{Catalog: name[0], Schema: name[1], Table: name[2]}
+	columns, err := profileColumns(description, opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) > maxProfileColumns {
+		profile.Warnings = append(profile.Warnings, fmt.Sprintf("only the first %d of %d columns were profiled; "+
+			"name the columns to profile the others", maxProfileColumns, len(columns)))
+		columns = columns[:maxProfileColumns]
+	}
+	profile.Columns = columns
+
+	if err := c.addStatistics(ctx, quoted, profile); err != nil {
+		profile.Warnings = append(profile.Warnings, "no statistics: "+err.Error())
+	}
+
+	profile.SamplePercent = opts.SamplePercent
+	if profile.SamplePercent == 0 {
+		profile.SamplePercent = 100
+		// Sample about twice the rows needed, so the limit rarely leaves the sample short
+		if profile.RowCount != nil && *profile.RowCount > 0 {
+			profile.SamplePercent = math.Min(100, 100*2*float64(sampleRows) / *profile.RowCount)
+		}
+	}
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = QuoteIdentifier(column.Name)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s TABLESAMPLE BERNOULLI (%s) LIMIT %d", strings.Join(names, ", "),
+		quoted, strconv.FormatFloat(profile.SamplePercent, 'f', -1, 64), sampleRows)
+	if err := c.preflight(ctx, query); err != nil {
+		return nil, err
+	}
+	sample, err := c.execute(ctx, query, resultLimits{maxRows: sampleRows})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample table: %w", err)
+	}
+	profile.SampledRows = len(sample.Rows)
+	for i := range profile.Columns {
+		profile.Columns[i].Sample = sampleColumn(sample.Rows, i, profile.Columns[i].Type, topValues)
+	}
+	return profile, nil
+}
+
+// profileColumns selects the named columns from the output of DESCRIBE, or all of them
+func profileColumns(description *QueryResult, names []string) ([]ColumnProfile, error) {
+	nameIdx, typeIdx := description.ColumnIndex("Column"), description.ColumnIndex("Type")
+	all := make([]ColumnProfile, 0, len(description.Rows))
+	for _, row := range description.Rows {
+		all = append(all, ColumnProfile{Name: rowString(row, nameIdx), Type: rowString(row, typeIdx)})
+	}
+	if len(names) == 0 {
+		return all, nil
+	}
+	columns := make([]ColumnProfile, 0, len(names))
+	for _, name := range names {
+		column, err := parseIdentifier(name)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, c := range all {
+			if c.Name == column {
+				columns = append(columns, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("column %q not found", column)
+		}
+	}
+	return columns, nil
+}
+
+// addStatistics adds the statistics of SHOW STATS to the profile
+func (c *Client) addStatistics(ctx context.Context, table string, profile *TableProfile) error {
+	stats, err := c.execute(ctx, "SHOW STATS FOR "+table, resultLimits{})
+	if err != nil {
+		return err
+	}
+	nameIdx := stats.ColumnIndex("column_name")
+	sizeIdx, distinctIdx := stats.ColumnIndex("data_size"), stats.ColumnIndex("distinct_values_count")
+	nullsIdx, rowsIdx := stats.ColumnIndex("nulls_fraction"), stats.ColumnIndex("row_count")
+	lowIdx, highIdx := stats.ColumnIndex("low_value"), stats.ColumnIndex("high_value")
+	if nameIdx < 0 {
+		return fmt.Errorf("SHOW STATS returned no column_name column")
+	}
+	for _, row := range stats.Rows {
+		// The summary row has no column name and carries the row count
+		if row[nameIdx] == nil {
+			profile.RowCount = statistic(row, rowsIdx)
+			continue
+		}
+		name := rowString(row, nameIdx)
+		for i := range profile.Columns {
+			column := &profile.Columns[i]
+			if column.Name != name {
+				continue
+			}
+			column.DataSize = statistic(row, sizeIdx)
+			column.DistinctCount = statistic(row, distinctIdx)
+			column.NullFraction = statistic(row, nullsIdx)
+			column.Low = rowString(row, lowIdx)
+			column.High = rowString(row, highIdx)
+		}
+	}
+	if profile.RowCount == nil {
+		return fmt.Errorf("the connector has no row count for the table")
+	}
+	return nil
+}
+
+// statistic returns a SHOW STATS value, or nil when it is unknown
+func statistic(row []interface{}, i int) *float64 {
+	if i < 0 || i >= len(row) {
+		return nil
+	}
+	switch v := row[i].(type) {
+	case float64:
+		if !math.IsNaN(v) {
+			return &v
+		}
+	case int64:
+		f := float64(v)
+		return &f
+	}
+	return nil
+}
+
+// sampleColumn profiles a column of the sampled rows
+func sampleColumn(rows [][]interface{}, col int, typeName string, topValues int) ColumnSample {
+	sample := ColumnSample{TopValues: []ValueCount{}, Values: []interface{}{}}
+	if len(rows) == 0 {
+		return sample
+	}
+	order := orderOf(typeName)
+	counts := make(map[string]*ValueCount)
+	var seen []*ValueCount
+	nulls := 0
+	for _, row := range rows {
+		value := row[col]
+		if value == nil {
+			nulls++
+			continue
+		}
+		key := valueKey(value)
+		count, ok := counts[key]
+		if !ok {
+			count = &ValueCount{Value: value}
+			counts[key] = count
+			seen = append(seen, count)
+		}
+		count.Count++
+		if order != nil {
+			if sample.Min == nil || order(value, sample.Min) < 0 {
+				sample.Min = value
+			}
+			if sample.Max == nil || order(value, sample.Max) > 0 {
+				sample.Max = value
+			}
+		}
+	}
+	sample.NullFraction = float64(nulls) / float64(len(rows))
+	sample.DistinctCount = len(seen)
+	sample.Min, sample.Max = shortValue(sample.Min), shortValue(sample.Max)
+	for _, count := range seen[:min(profileSampleValues, len(seen))] {
+		sample.Values = append(sample.Values, shortValue(count.Value))
+	}
+
+	// The most frequent values, the first seen first among equally frequent ones
+	sort.SliceStable(seen, func(i, j int) bool { return seen[i].Count > seen[j].Count })
+	for _, count := range seen[:min(topValues, len(seen))] {
+		sample.TopValues = append(sample.TopValues, ValueCount{Value: shortValue(count.Value), Count: count.Count})
+	}
+	return sample
+}
+
+// valueKey identifies equal values of a column
+func valueKey(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// shortValue cuts long strings, so a few large values do not dominate the profile
+func shortValue(value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok || len(s) <= maxProfileValueLength {
+		return value
+	}
+	cut := maxProfileValueLength
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
+
+// orderOf returns a comparison of the converted values of a type, or nil when min and
+// max are not useful for it. Decimals and large bigints arrive as strings.
+func orderOf(typeName string) func(a, b interface{}) int {
+	t, err := parseType(typeName)
+	if err != nil {
+		return nil
+	}
+	switch t.name {
+	case "tinyint", "smallint", "integer", "bigint", "real", "double", "decimal":
+		return func(a, b interface{}) int {
+			return compareFloat(numericValue(a), numericValue(b))
+		}
+	case "varchar", "char", "date", "time", "timestamp":
+		// Dates and times without a zone are rendered with a fixed layout, which sorts as text
+		return func(a, b interface{}) int {
+			return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+		}
+	case "timestamp with time zone":
+		return func(a, b interface{}) int {
+			ta, errA := time.Parse(time.RFC3339Nano, fmt.Sprint(a))
+			tb, errB := time.Parse(time.RFC3339Nano, fmt.Sprint(b))
+			if errA != nil || errB != nil {
+				return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+			}
+			return ta.Compare(tb)
+		}
+	}
+	return nil
+}
+
+// numericValue returns a converted number as a float64; NaN when it is not a number
+func numericValue(v interface{}) float64 {
+	switch n := v.(type) {
+	case int64:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int16:
+		return float64(n)
+	case int8:
+		return float64(n)
+	case int:
+		return float64(n)
+	case float64:
+		return n
+	case float32:
+		return float64(n)
+	case json.Number:
+		f, err := n.Float64()
+		if err != nil {
+			return math.NaN()
+		}
+		return f
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return math.NaN()
+		}
+		return f
+	}
+	return math.NaN()
+}
+
+// compareFloat orders numbers, with NaN after every other value
+func compareFloat(a, b float64) int {
+	switch {
+	case math.IsNaN(a) && math.IsNaN(b):
+		return 0
+	case math.IsNaN(a):
+		return 1
+	case math.IsNaN(b):
+		return -1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
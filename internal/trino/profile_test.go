@@ -0,0 +1,133 @@
+package trino
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+func TestProfileTable(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	srv.SetResult(`DESCRIBE "hive"."web"."events"`, trinotest.Result{
+		Columns: []trinotest.Column{{Name: "Column", Type: "varchar"}, {Name: "Type", Type: "varchar"}},
+		Rows:    [][]interface{}{{"id", "bigint"}, {"country", "varchar"}, {"amount", "decimal(10,2)"}, {"flag", "boolean"}},
+	})
+	srv.SetResult(`SHOW STATS FOR "hive"."web"."events"`, trinotest.Result{
+		Columns: []trinotest.Column{
+			{Name: "column_name", Type: "varchar"},
+			{Name: "data_size", Type: "double"},
+			{Name: "distinct_values_count", Type: "double"},
+			{Name: "nulls_fraction", Type: "double"},
+			{Name: "row_count", Type: "double"},
+			{Name: "low_value", Type: "varchar"},
+			{Name: "high_value", Type: "varchar"},
+		},
+		Rows: [][]interface{}{
+			{"id", nil, 100000.0, 0.0, nil, "1", "100000"},
+			{"country", 250000.0, 40.0, 0.1, nil, nil, nil},
+			{nil, nil, nil, nil, 100000.0, nil, nil},
+		},
+	})
+	srv.SetResult(`SELECT "id", "country", "amount", "flag" FROM "hive"."web"."events" TABLESAMPLE BERNOULLI (2) LIMIT 1000`, trinotest.Result{
+		Columns: []trinotest.Column{
+			{Name: "id", Type: "bigint"},
+			{Name: "country", Type: "varchar"},
+			{Name: "amount", Type: "decimal(10,2)"},
+			{Name: "flag", Type: "boolean"},
+		},
+		Rows: [][]interface{}{
+			{1, "DE", "10.50", true},
+			{2, "US", "9.99", false},
+			{3, "DE", "100.00", true},
+			{4, nil, nil, true},
+			{5, "FR", "9.99", false},
+			{6, "DE", "20.00", true},
+		},
+	})
+
+	client, err := NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	profile, err := client.ProfileTable(ctx, "hive", "web", "events", ProfileOptions{})
+	if err != nil {
+		t.Fatalf("ProfileTable() error = %v", err)
+	}
+	if profile.RowCount == nil || *profile.RowCount != 100000 || profile.SamplePercent != 2 || profile.SampledRows != 6 {
+		t.Fatalf("ProfileTable() = row count %v, %g%% sampled, %d rows; want 100000, 2%%, 6 rows",
+			profile.RowCount, profile.SamplePercent, profile.SampledRows)
+	}
+	if len(profile.Columns) != 4 || len(profile.Warnings) != 0 {
+		t.Fatalf("ProfileTable() = %d columns, warnings %q; want 4 columns and no warnings", len(profile.Columns), profile.Warnings)
+	}
+
+	id := profile.Columns[0]
+	if id.DistinctCount == nil || *id.DistinctCount != 100000 || id.NullFraction == nil || *id.NullFraction != 0 ||
+		id.Low != "1" || id.High != "100000" || id.DataSize != nil {
+		t.Errorf("id statistics = %+v", id)
+	}
+	if id.Sample.Min != int64(1) || id.Sample.Max != int64(6) || id.Sample.DistinctCount != 6 {
+		t.Errorf("id sample = %+v, want min 1, max 6 and 6 distinct values", id.Sample)
+	}
+
+	country := profile.Columns[1].Sample
+	wantTop := []ValueCount{{"DE", 3}, {"US", 1}, {"FR", 1}}
+	if !reflect.DeepEqual(country.TopValues, wantTop) {
+		t.Errorf("country top values = %v, want %v", country.TopValues, wantTop)
+	}
+	if country.NullFraction != 1.0/6 || country.DistinctCount != 3 || country.Min != "DE" || country.Max != "US" ||
+		!reflect.DeepEqual(country.Values, []interface{}{"DE", "US", "FR"}) {
+		t.Errorf("country sample = %+v", country)
+	}
+
+	// Decimals are ordered as numbers, not as text
+	if amount := profile.Columns[2].Sample; amount.Min != "9.99" || amount.Max != "100.00" || amount.TopValues[0] != (ValueCount{"9.99", 2}) {
+		t.Errorf("amount sample = %+v, want min 9.99, max 100.00 and 9.99 most frequent", amount)
+	}
+	if flag := profile.Columns[3].Sample; flag.Min != nil || flag.Max != nil || flag.DistinctCount != 2 {
+		t.Errorf("flag sample = %+v, want no min or max and 2 distinct values", flag)
+	}
+
+	// Named columns, a given sample percent and no statistics
+	srv.SetResult(`DESCRIBE "memory"."default"."t"`, trinotest.Result{
+		Columns: []trinotest.Column{{Name: "Column", Type: "varchar"}, {Name: "Type", Type: "varchar"}},
+		Rows:    [][]interface{}{{"a", "bigint"}, {"Mixed Case", "varchar"}},
+	})
+	srv.SetResult(`SELECT "Mixed Case" FROM "memory"."default"."t" TABLESAMPLE BERNOULLI (50) LIMIT 10`, trinotest.Result{
+		Columns: []trinotest.Column{{Name: "Mixed Case", Type: "varchar"}},
+		Rows:    [][]interface{}{{strings.Repeat("é", 150)}},
+	})
+	profile, err = client.ProfileTable(ctx, "", "", "t", ProfileOptions{
+		Columns: []string{`"Mixed Case"`}, SamplePercent: 50, SampleRows: 10, TopValues: 1,
+	})
+	if err != nil {
+		t.Fatalf("ProfileTable(t) error = %v", err)
+	}
+	if profile.RowCount != nil || len(profile.Warnings) != 1 || !strings.HasPrefix(profile.Warnings[0], "no statistics") {
+		t.Errorf("ProfileTable(t) = row count %v, warnings %q; want a warning about missing statistics", profile.RowCount, profile.Warnings)
+	}
+	if len(profile.Columns) != 1 || profile.Columns[0].NullFraction != nil {
+		t.Fatalf("ProfileTable(t) columns = %+v, want the Mixed Case column without statistics", profile.Columns)
+	}
+	// Long values are cut on a character boundary
+	if value, _ := profile.Columns[0].Sample.Values[0].(string); len(value) > maxProfileValueLength+len("…") || !strings.HasSuffix(value, "é…") {
+		t.Errorf("long sample value = %q, want it cut", value)
+	}
+
+	for _, opts := range []ProfileOptions{
+		{Columns: []string{"missing"}},
+		{Columns: []string{"a.b"}},
+		{SamplePercent: 150},
+	} {
+		if _, err := client.ProfileTable(ctx, "", "", "t", opts); err == nil {
+			t.Errorf("ProfileTable(%+v) succeeded, want error", opts)
+		}
+	}
+}
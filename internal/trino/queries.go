@@ -0,0 +1,275 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+// ErrShuttingDown is returned for queries started after Shutdown began
+var ErrShuttingDown = errors.New("server is shutting down")
+
+const (
+	// progressPeriod is how often the driver reports the progress of a running query
+	progressPeriod = time.Second
+	// recentQueries is how many finished queries are kept for query_status
+	recentQueries = 100
+)
+
+// queryIDPattern matches Trino query IDs, e.g. 20240101_120000_00042_abcde
+var queryIDPattern = regexp.MustCompile(`^[0-9]{8}_[0-9]{6}_[0-9]{5}_[0-9a-z]{5}$`)
+
+// QueryInfo describes a query started by this server
+type QueryInfo struct {
+	QueryID string `json:"query_id"`
+	// State is the Trino query state, e.g. QUEUED, RUNNING, FINISHED or FAILED
+	State              string  `json:"state"`
+	User               string  `json:"user,omitempty"`
+	Query              string  `json:"query"`
+	ElapsedMillis      int64   `json:"elapsed_ms"`
+	ProgressPercentage float64 `json:"progress_percentage"`
+	CompletedSplits    int     `json:"completed_splits"`
+	TotalSplits        int     `json:"total_splits"`
+	ProcessedRows      int64   `json:"processed_rows"`
+	ProcessedBytes     int64   `json:"processed_bytes"`
+	ErrorCode          string  `json:"error_code,omitempty"`
+	// Running is set while this server is still executing the query
+	Running bool `json:"running"`
+}
+
+// runningQuery is a query in flight. The driver's progress callback records the
+// Trino query ID and statistics, which stay available once the query has finished.
+type runningQuery struct {
+	query    string
+	user     string
+	started  time.Time
+	cancel   context.CancelFunc
+	progress func(QueryInfo)
+
+	mu       sync.Mutex
+	id       string
+	finished bool
+	info     trino.QueryProgressInfo
+}
+
+// Update implements trino.ProgressUpdater. The driver keeps the callback on its pooled
+// connection, so it may be called for later statements on that connection; reports
+// after the query finished or for another query ID are ignored.
+func (r *runningQuery) Update(info trino.QueryProgressInfo) {
+	r.mu.Lock()
+	if r.finished || (r.id != "" && info.QueryId != r.id) {
+		r.mu.Unlock()
+		return
+	}
+	r.id = info.QueryId
+	r.info = info
+	r.mu.Unlock()
+	if r.progress != nil {
+		r.progress(r.snapshot())
+	}
+}
+
+// noProgress is the progress callback of statements whose progress is not tracked. It
+// replaces the callback of an earlier query that the driver kept on the connection.
+type noProgress struct{}
+
+// Update implements trino.ProgressUpdater
+func (noProgress) Update(trino.QueryProgressInfo) {}
+
+type progressKey struct{}
+
+// WithProgress returns a context whose queries report their progress to fn. The driver
+// reports when a query starts, changes state and about once per progressPeriod while
+// it returns data; fn is called on the driver's goroutine and must not block.
+func WithProgress(ctx context.Context, fn func(QueryInfo)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+func progressFromContext(ctx context.Context) func(QueryInfo) {
+	fn, _ := ctx.Value(progressKey{}).(func(QueryInfo))
+	return fn
+}
+
+// queryID returns the Trino query ID, or an empty string until the driver reports it
+func (r *runningQuery) queryID() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.id
+}
+
+// snapshot describes the query as last reported by the driver
+func (r *runningQuery) snapshot() QueryInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := r.info.QueryStats
+	info := QueryInfo{
+		QueryID:            r.id,
+		State:              stats.State,
+		User:               r.user,
+		Query:              r.query,
+		ElapsedMillis:      stats.ElapsedTimeMillis,
+		ProgressPercentage: float64(stats.ProgressPercentage),
+		CompletedSplits:    stats.CompletedSplits,
+		TotalSplits:        stats.TotalSplits,
+		ProcessedRows:      stats.ProcessedRows,
+		ProcessedBytes:     stats.ProcessedBytes,
+		Running:            !r.finished,
+	}
+	if !r.finished {
+		info.ElapsedMillis = time.Since(r.started).Milliseconds()
+	}
+	return info
+}
+
+// inflightQueries tracks running queries so they can be inspected, killed, or waited
+// for and cancelled at shutdown. The most recently finished queries are kept as well.
+type inflightQueries struct {
+	mu      sync.Mutex
+	closing bool
+	nextID  int
+	queries map[int]*runningQuery
+	recent  []*runningQuery
+	wg      sync.WaitGroup
+}
+
+// start registers a query and returns its context, the query for the driver's progress
+// callback and a function to call when it ends. Once shutdown has begun it refuses new
+// queries with ErrShuttingDown.
+func (q *inflightQueries) start(ctx context.Context, query string) (context.Context, *runningQuery, func(), error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closing {
+		return nil, nil, nil, ErrShuttingDown
+	}
+	if q.queries == nil {
+		q.queries = make(map[int]*runningQuery)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	q.nextID++
+	id := q.nextID
+	r := &runningQuery{
+		query:    query,
+		user:     userFromContext(ctx),
+		started:  time.Now(),
+		cancel:   cancel,
+		progress: progressFromContext(ctx),
+	}
+	q.queries[id] = r
+	q.wg.Add(1)
+
+	return ctx, r, func() {
+		r.mu.Lock()
+		r.finished = true
+		r.mu.Unlock()
+
+		q.mu.Lock()
+		delete(q.queries, id)
+		if r.queryID() != "" {
+			q.recent = append(q.recent, r)
+			if len(q.recent) > recentQueries {
+				q.recent = q.recent[len(q.recent)-recentQueries:]
+			}
+		}
+		q.mu.Unlock()
+		cancel()
+		q.wg.Done()
+	}, nil
+}
+
+// find returns the running or recently finished query with a Trino query ID
+func (q *inflightQueries) find(queryID string) (*runningQuery, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, r := range q.queries {
+		if r.queryID() == queryID {
+			return r, true
+		}
+	}
+	for i := len(q.recent) - 1; i >= 0; i-- {
+		if q.recent[i].queryID() == queryID {
+			return q.recent[i], true
+		}
+	}
+	return nil, false
+}
+
+// running describes the running queries of a user, or of all users when user is empty
+func (q *inflightQueries) running(user string) []QueryInfo {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	queries := make([]QueryInfo, 0, len(q.queries))
+	for _, r := range q.queries {
+		if user != "" && r.user != user {
+			continue
+		}
+		queries = append(queries, r.snapshot())
+	}
+	return queries
+}
+
+// RunningQueries describes the queries this server is running for the caller's user,
+// or for every user when the context carries none
+func (c *Client) RunningQueries(ctx context.Context) []QueryInfo {
+	return c.inflight.running(userFromContext(ctx))
+}
+
+// QueryStatus reports the state of a query. The coordinator's view in
+// system.runtime.queries is combined with the statistics the driver reported for
+// queries started by this server.
+func (c *Client) QueryStatus(ctx context.Context, queryID string) (*QueryInfo, error) {
+	if !queryIDPattern.MatchString(queryID) {
+		return nil, fmt.Errorf("invalid query ID %q", queryID)
+	}
+
+	// Statistics of another user's query are not shown; the coordinator applies its own
+	// access control to system.runtime.queries
+	var info QueryInfo
+	r, local := c.inflight.find(queryID)
+	if local && r.user != "" && userFromContext(ctx) != r.user {
+		local = false
+	}
+	if local {
+		info = r.snapshot()
+	}
+
+	query := "SELECT state, \"user\", query, error_code, " +
+		"date_diff('millisecond', created, coalesce(\"end\", current_timestamp)) AS elapsed_ms " +
+		"FROM system.runtime.queries WHERE query_id = " + quoteLiteral(queryID)
+	result, err := c.execute(ctx, query, resultLimits{maxRows: 1})
+	switch {
+	case err != nil && !local:
+		return nil, fmt.Errorf("failed to look up query %s: %w", queryID, err)
+	case err == nil && len(result.Rows) > 0:
+		row := result.Rows[0]
+		info.QueryID = queryID
+		info.State, _ = row[0].(string)
+		info.User, _ = row[1].(string)
+		info.Query, _ = row[2].(string)
+		info.ErrorCode, _ = row[3].(string)
+		if elapsed, ok := row[4].(int64); ok {
+			info.ElapsedMillis = elapsed
+		}
+	case !local:
+		return nil, fmt.Errorf("query %s not found", queryID)
+	}
+	return &info, nil
+}
+
+// KillQuery cancels a query this server is running, which cancels it on the
+// coordinator. Queries run as a Trino user may only be killed by that user.
+func (c *Client) KillQuery(ctx context.Context, queryID string) error {
+	r, ok := c.inflight.find(queryID)
+	if !ok || !r.snapshot().Running {
+		return fmt.Errorf("query %s is not running on this server", queryID)
+	}
+	if r.user != "" && userFromContext(ctx) != r.user {
+		return fmt.Errorf("query %s belongs to another user", queryID)
+	}
+	r.cancel()
+	return nil
+}
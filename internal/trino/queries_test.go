@@ -0,0 +1,160 @@
+package trino
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+func TestQueryStatusAndKill(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	srv.SetResult("SELECT * FROM huge", trinotest.Result{Block: true})
+	srv.HandleFunc(func(sql string) (trinotest.Result, bool) {
+		if !strings.Contains(sql, "FROM system.runtime.queries") {
+			return trinotest.Result{}, false
+		}
+		return trinotest.Result{
+			Columns: []trinotest.Column{
+				{Name: "state", Type: "varchar"},
+				{Name: "user", Type: "varchar"},
+				{Name: "query", Type: "varchar"},
+				{Name: "error_code", Type: "varchar"},
+				{Name: "elapsed_ms", Type: "bigint"},
+			},
+			Rows: [][]interface{}{{"RUNNING", "alice", "SELECT * FROM huge", nil, 1500}},
+		}, true
+	})
+
+	client, err := NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	alice := WithUser(context.Background(), "alice")
+	queryErr := make(chan error, 1)
+	go func() {
+		_, err := client.ExecuteQuery(alice, "SELECT * FROM huge")
+		queryErr <- err
+	}()
+
+	// The driver reports the query ID through the progress callback
+	var queryID string
+	deadline := time.Now().Add(5 * time.Second)
+	for queryID == "" {
+		if time.Now().After(deadline) {
+			t.Fatalf("RunningQueries() = %+v, want the query with its ID", client.RunningQueries(alice))
+		}
+		for _, q := range client.RunningQueries(alice) {
+			queryID = q.QueryID
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := client.RunningQueries(WithUser(context.Background(), "bob")); len(got) != 0 {
+		t.Errorf("RunningQueries() for another user = %+v, want none", got)
+	}
+
+	info, err := client.QueryStatus(alice, queryID)
+	if err != nil {
+		t.Fatalf("QueryStatus() error = %v", err)
+	}
+	if info.QueryID != queryID || info.State != "RUNNING" || info.User != "alice" || info.ElapsedMillis != 1500 || !info.Running {
+		t.Errorf("QueryStatus() = %+v, want running query %s of alice", info, queryID)
+	}
+	if _, err := client.QueryStatus(alice, "1; DROP TABLE x"); err == nil {
+		t.Error("QueryStatus() accepted an invalid query ID")
+	}
+
+	if err := client.KillQuery(WithUser(context.Background(), "bob"), queryID); err == nil {
+		t.Error("KillQuery() by another user succeeded")
+	}
+	if err := client.KillQuery(alice, "20240101_000000_99999_fake0"); err == nil {
+		t.Error("KillQuery() of a query not started by the server succeeded")
+	}
+	if err := client.KillQuery(alice, queryID); err != nil {
+		t.Fatalf("KillQuery() error = %v", err)
+	}
+
+	select {
+	case err := <-queryErr:
+		if err == nil {
+			t.Error("ExecuteQuery() succeeded, want an error after kill")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ExecuteQuery() did not return after KillQuery")
+	}
+	deadline = time.Now().Add(2 * time.Second)
+	for !contains(srv.Cancelled(), queryID) {
+		if time.Now().After(deadline) {
+			t.Fatalf("query %s was not cancelled on the coordinator (cancelled: %v)", queryID, srv.Cancelled())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Finished queries remain known but can no longer be killed
+	info, err = client.QueryStatus(alice, queryID)
+	if err != nil || info.Running {
+		t.Errorf("QueryStatus() after kill = %+v, %v; want a finished query", info, err)
+	}
+	if err := client.KillQuery(alice, queryID); err == nil {
+		t.Error("KillQuery() of a finished query succeeded")
+	}
+}
+
+func TestQueryStatusAfterPing(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	srv.SetResult("SELECT * FROM small", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "n", Type: "bigint"}},
+		Rows:    [][]interface{}{{1}},
+	})
+	srv.SetResult("SELECT 1", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "_col0", Type: "integer"}},
+		Rows:    [][]interface{}{{1}},
+	})
+
+	client, err := NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	var mu sync.Mutex
+	var queryID string
+	ctx := WithProgress(context.Background(), func(info QueryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		if queryID == "" {
+			queryID = info.QueryID
+		}
+	})
+	if _, err := client.ExecuteQuery(ctx, "SELECT * FROM small"); err != nil {
+		t.Fatalf("ExecuteQuery() error = %v", err)
+	}
+	mu.Lock()
+	id := queryID
+	mu.Unlock()
+	if id == "" {
+		t.Fatal("ExecuteQuery() did not report its query ID")
+	}
+
+	// The driver keeps the progress callback on its pooled connection, so later
+	// statements on it must not report to the finished query
+	for i := 0; i < 3; i++ {
+		if err := client.Ping(context.Background()); err != nil {
+			t.Fatalf("Ping() error = %v", err)
+		}
+	}
+	info, err := client.QueryStatus(context.Background(), id)
+	if err != nil {
+		t.Fatalf("QueryStatus() after Ping() error = %v", err)
+	}
+	if info.QueryID != id || info.Query != "SELECT * FROM small" || info.Running {
+		t.Errorf("QueryStatus() after Ping() = %+v, want finished query %s", info, id)
+	}
+}
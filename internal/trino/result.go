@@ -0,0 +1,126 @@
+package trino
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// truncationHint is returned with truncated results to steer the caller towards a narrower query
+const truncationHint = "The result was truncated. Narrow the query with a WHERE clause, select fewer columns, " +
+	"aggregate the data, or add a LIMIT clause."
+
+// Column describes a result column
+type Column struct {
+	Name string `json:"name"`
+	// Type is the Trino type, e.g. "decimal(38,2)" or "timestamp(3) with time zone"
+	Type string `json:"type"`
+}
+
+// QueryResult holds the columns and rows returned by a query and whether any rows were dropped.
+// Rows are positional: Rows[i][j] is the value of Columns[j], so column order is kept and
+// duplicate column names do not collide.
+type QueryResult struct {
+	Columns  []Column        `json:"columns"`
+	Rows     [][]interface{} `json:"rows"`
+	RowCount int             `json:"row_count"`
+	// Truncated is set when the result hit the row or size limit and rows were dropped
+	Truncated bool   `json:"truncated"`
+	Hint      string `json:"hint,omitempty"`
+
+	// size is the serialized size of Rows, when it was measured while collecting them
+	size int
+}
+
+// ColumnIndex returns the position of the first column with the given name, or -1
+func (r *QueryResult) ColumnIndex(name string) int {
+	for i, col := range r.Columns {
+		if col.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Strings returns the non-null string values of the named column
+func (r *QueryResult) Strings(name string) []string {
+	idx := r.ColumnIndex(name)
+	values := make([]string, 0, len(r.Rows))
+	if idx < 0 {
+		return values
+	}
+	for _, row := range r.Rows {
+		if s, ok := row[idx].(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// columnsFromTypes describes result columns using the driver's type information
+func columnsFromTypes(types []*sql.ColumnType) []Column {
+	columns := make([]Column, len(types))
+	for i, ct := range types {
+		columns[i] = Column{Name: ct.Name(), Type: trinoTypeName(ct)}
+	}
+	return columns
+}
+
+// unboundedLength is the length Trino reports for varchar without an explicit length
+const unboundedLength = 2147483647
+
+// trinoTypeName reconstructs the full Trino type name. The driver reports the base type
+// name in upper case (or the full name for array, map and row) with parameters separately.
+func trinoTypeName(ct *sql.ColumnType) string {
+	name := strings.ToLower(ct.DatabaseTypeName())
+	switch name {
+	case "decimal":
+		if precision, scale, ok := ct.DecimalSize(); ok {
+			return fmt.Sprintf("decimal(%d,%d)", precision, scale)
+		}
+	case "varchar", "char":
+		if length, ok := ct.Length(); ok && length != unboundedLength {
+			return fmt.Sprintf("%s(%d)", name, length)
+		}
+	case "time", "timestamp", "time with time zone", "timestamp with time zone":
+		if precision, _, ok := ct.DecimalSize(); ok {
+			base, zone, _ := strings.Cut(name, " ")
+			return strings.TrimSpace(fmt.Sprintf("%s(%d) %s", base, precision, zone))
+		}
+	}
+	return name
+}
+
+// resultLimits bounds how much of a result is collected; zero values mean unlimited
+type resultLimits struct {
+	maxRows  int
+	maxBytes int
+}
+
+// truncate marks the result as truncated, recording why
+func (r *QueryResult) truncate(reason string) {
+	r.Truncated = true
+	r.Hint = fmt.Sprintf("%s: %s", reason, truncationHint)
+}
+
+// rowsSize returns the serialized size of the rows, measuring it if it was not
+// recorded while collecting them
+func (r *QueryResult) rowsSize() int {
+	if r.size == 0 {
+		for _, row := range r.Rows {
+			r.size += serializedSize(row)
+		}
+	}
+	return r.size
+}
+
+// serializedSize estimates how many bytes a row adds to the JSON encoded result
+func serializedSize(row interface{}) int {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return len(fmt.Sprint(row))
+	}
+	// Account for the separator between rows
+	return len(data) + 1
+}
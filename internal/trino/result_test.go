@@ -0,0 +1,80 @@
+package trino
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+// newRowsClient returns a client of a fake coordinator that answers SELECT id FROM t
+// with the rows 1 to n
+func newRowsClient(t *testing.T, n int) *Client {
+	t.Helper()
+	srv := trinotest.NewServer()
+	t.Cleanup(srv.Close)
+	rows := make([][]interface{}, n)
+	for i := range rows {
+		rows[i] = []interface{}{i + 1}
+	}
+	srv.SetResult("SELECT id FROM t", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "id", Type: "bigint"}},
+		Rows:    rows,
+	})
+
+	client, err := NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestResultLimits(t *testing.T) {
+	// Each row [n] with n < 10 takes 3 bytes plus a separator
+	tests := []struct {
+		name     string
+		limits   resultLimits
+		wantRows int
+		wantHint string
+	}{
+		{"unlimited", resultLimits{}, 5, ""},
+		{"row cap", resultLimits{maxRows: 3}, 3, "row limit of 3 reached"},
+		{"rows within cap", resultLimits{maxRows: 5}, 5, ""},
+		{"byte cap", resultLimits{maxBytes: 10}, 2, "result size limit of 10 bytes reached"},
+		{"first row over byte cap", resultLimits{maxBytes: 3}, 0, "result size limit of 3 bytes reached"},
+	}
+
+	client := newRowsClient(t, 5)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := client.execute(context.Background(), "SELECT id FROM t", tt.limits)
+			if err != nil {
+				t.Fatalf("execute() error = %v", err)
+			}
+			if result.RowCount != tt.wantRows || len(result.Rows) != tt.wantRows {
+				t.Errorf("execute() returned %d rows (row_count %d), want %d", len(result.Rows), result.RowCount, tt.wantRows)
+			}
+			if result.Truncated != (tt.wantHint != "") {
+				t.Errorf("execute() truncated = %v, want %v", result.Truncated, tt.wantHint != "")
+			}
+			if tt.wantHint != "" && (!strings.HasPrefix(result.Hint, tt.wantHint+": ") || !strings.HasSuffix(result.Hint, truncationHint)) {
+				t.Errorf("execute() hint = %q, want %q followed by the truncation hint", result.Hint, tt.wantHint)
+			}
+			if tt.wantHint == "" && result.Hint != "" {
+				t.Errorf("execute() hint = %q, want none", result.Hint)
+			}
+		})
+	}
+}
+
+func TestSerializedSize(t *testing.T) {
+	if got := serializedSize([]interface{}{1}); got != len(`[1]`)+1 {
+		t.Errorf("serializedSize() = %d, want the JSON length plus a separator", got)
+	}
+	// Values JSON cannot encode fall back to their printed form
+	if got := serializedSize(func() {}); got <= 0 {
+		t.Errorf("serializedSize() of an unencodable value = %d, want a positive estimate", got)
+	}
+}
@@ -0,0 +1,232 @@
+package trino
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrResultNotFound is returned when a result handle is unknown, expired or already fully read
+var ErrResultNotFound = errors.New("result handle not found or expired; re-run the query")
+
+// ResultPage is one page of a query result. Handle and NextPageToken are set while
+// more rows are buffered on the server and can be fetched with FetchResults.
+type ResultPage struct {
+	QueryResult
+	// Offset is the index of the first row of this page within the whole result
+	Offset int `json:"offset"`
+	// TotalRows is the number of rows buffered on the server for this result
+	TotalRows     int    `json:"total_rows"`
+	Handle        string `json:"handle,omitempty"`
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+// bufferedResult is a query result held on the server between page fetches
+type bufferedResult struct {
+	result   *QueryResult
+	owner    string
+	size     int
+	ttl      time.Duration
+	lastUsed time.Time
+}
+
+// resultStore holds buffered results for pagination. It is bounded by the number of
+// results it keeps and their total serialized size (evicting the least recently used)
+// and expires results unused for their TTL, which defaults to the store's.
+type resultStore struct {
+	mu         sync.Mutex
+	results    map[string]*bufferedResult
+	maxResults int
+	maxBytes   int // zero means unbounded
+	bytes      int
+	ttl        time.Duration
+	done       chan struct{}
+}
+
+// newResultStore creates a store and starts a janitor that drops expired results
+func newResultStore(maxResults, maxBytes int, ttl time.Duration) *resultStore {
+	s := &resultStore{
+		results:    make(map[string]*bufferedResult),
+		maxResults: maxResults,
+		maxBytes:   maxBytes,
+		ttl:        ttl,
+		done:       make(chan struct{}),
+	}
+	go s.janitor()
+	return s
+}
+
+func (s *resultStore) janitor() {
+	ticker := time.NewTicker(s.ttl / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.mu.Lock()
+			s.expireLocked(time.Now())
+			s.mu.Unlock()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// close stops the janitor and releases all buffered results
+func (s *resultStore) close() {
+	close(s.done)
+	s.mu.Lock()
+	s.results = make(map[string]*bufferedResult)
+	s.bytes = 0
+	s.mu.Unlock()
+}
+
+// removeLocked drops a result and its share of the byte budget
+func (s *resultStore) removeLocked(handle string) {
+	if buf, ok := s.results[handle]; ok {
+		s.bytes -= buf.size
+		delete(s.results, handle)
+	}
+}
+
+// expireLocked removes results unused for longer than their TTL
+func (s *resultStore) expireLocked(now time.Time) {
+	for handle, buf := range s.results {
+		if now.Sub(buf.lastUsed) > buf.ttl {
+			s.removeLocked(handle)
+		}
+	}
+}
+
+// put stores a result for its owner with the store's TTL and returns its handle
+func (s *resultStore) put(result *QueryResult, owner string) (string, error) {
+	return s.putFor(result, owner, s.ttl)
+}
+
+// putFor stores a result for its owner with its own TTL and returns its handle,
+// evicting the least recently used results until it fits. A result larger than the
+// whole byte budget is refused rather than emptying the store.
+func (s *resultStore) putFor(result *QueryResult, owner string, ttl time.Duration) (string, error) {
+	size := result.rowsSize()
+	if s.maxBytes > 0 && size > s.maxBytes {
+		return "", fmt.Errorf("result of %d bytes exceeds the result buffer budget of %d bytes", size, s.maxBytes)
+	}
+	handle, err := newHandle()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.expireLocked(now)
+	for len(s.results) >= s.maxResults || (s.maxBytes > 0 && s.bytes+size > s.maxBytes) {
+		var oldest string
+		for h, buf := range s.results {
+			if oldest == "" || buf.lastUsed.Before(s.results[oldest].lastUsed) {
+				oldest = h
+			}
+		}
+		s.removeLocked(oldest)
+	}
+	s.results[handle] = &bufferedResult{result: result, owner: owner, size: size, ttl: ttl, lastUsed: now}
+	s.bytes += size
+	return handle, nil
+}
+
+// get returns a buffered result and refreshes its TTL. Results of other owners are
+// reported as not found so handles cannot be probed.
+func (s *resultStore) get(handle, owner string) (*QueryResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.expireLocked(now)
+	buf, ok := s.results[handle]
+	if !ok || buf.owner != owner {
+		return nil, ErrResultNotFound
+	}
+	buf.lastUsed = now
+	return buf.result, nil
+}
+
+// has reports whether a result is still buffered, without refreshing its TTL
+func (s *resultStore) has(handle string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked(time.Now())
+	_, ok := s.results[handle]
+	return ok
+}
+
+// release drops a result once it has been read completely
+func (s *resultStore) release(handle string) {
+	s.mu.Lock()
+	s.removeLocked(handle)
+	s.mu.Unlock()
+}
+
+// page slices rows starting at offset, bounded by the row count and serialized size.
+// At least one row is returned so a single oversized row cannot stall pagination.
+func page(result *QueryResult, offset int, limits resultLimits) *ResultPage {
+	p := &ResultPage{
+		QueryResult: QueryResult{
+			Columns:   result.Columns,
+			Truncated: result.Truncated,
+			Hint:      result.Hint,
+		},
+		Offset:    offset,
+		TotalRows: len(result.Rows),
+	}
+
+	end := offset
+	size := 0
+	for end < len(result.Rows) {
+		if limits.maxRows > 0 && end-offset >= limits.maxRows {
+			break
+		}
+		if limits.maxBytes > 0 {
+			size += serializedSize(result.Rows[end])
+			if size > limits.maxBytes && end > offset {
+				break
+			}
+		}
+		end++
+	}
+
+	p.Rows = result.Rows[offset:end]
+	p.RowCount = len(p.Rows)
+	if end < len(result.Rows) {
+		p.NextPageToken = encodePageToken(end)
+	}
+	return p
+}
+
+func newHandle() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate result handle: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodePageToken(token string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid page token")
+	}
+	offset, err := strconv.Atoi(string(data))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid page token")
+	}
+	return offset, nil
+}
@@ -0,0 +1,172 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+func testResult(n int) *QueryResult {
+	result := &QueryResult{Columns: []Column{{Name: "id", Type: "integer"}}}
+	for i := 0; i < n; i++ {
+		result.Rows = append(result.Rows, []interface{}{i})
+	}
+	result.RowCount = n
+	return result
+}
+
+func TestFetchResultsWalksAllPages(t *testing.T) {
+	client := &Client{
+		config:  &config.TrinoConfig{MaxRows: 4, MaxResultBytes: 1 << 20},
+		results: newResultStore(2, 0, time.Minute),
+	}
+	defer client.results.close()
+
+	result := testResult(10)
+	first := page(result, 0, client.pageLimits(3))
+	if first.RowCount != 3 || first.NextPageToken == "" {
+		t.Fatalf("first page = %d rows, token %q; want 3 rows and a token", first.RowCount, first.NextPageToken)
+	}
+	handle, err := client.results.put(result, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := first.RowCount
+	token := first.NextPageToken
+	for token != "" {
+		p, err := client.FetchResults(context.Background(), handle, token, 0)
+		if err != nil {
+			t.Fatalf("FetchResults() error = %v", err)
+		}
+		if p.Offset != seen {
+			t.Errorf("page offset = %d, want %d", p.Offset, seen)
+		}
+		if p.RowCount > 4 {
+			t.Errorf("page has %d rows, want at most MaxRows (4)", p.RowCount)
+		}
+		seen += p.RowCount
+		token = p.NextPageToken
+	}
+	if seen != 10 {
+		t.Errorf("read %d rows across pages, want 10", seen)
+	}
+
+	// The buffer is released after the last page
+	if _, err := client.FetchResults(context.Background(), handle, encodePageToken(0), 0); !errors.Is(err, ErrResultNotFound) {
+		t.Errorf("FetchResults() after last page error = %v, want ErrResultNotFound", err)
+	}
+}
+
+func TestPageByteLimit(t *testing.T) {
+	result := testResult(10)
+	rowSize := serializedSize(result.Rows[0])
+
+	p := page(result, 0, resultLimits{maxRows: 100, maxBytes: rowSize*2 + 1})
+	if p.RowCount != 2 {
+		t.Errorf("page has %d rows, want 2", p.RowCount)
+	}
+
+	// A single row larger than the limit is still returned so paging makes progress
+	p = page(result, 0, resultLimits{maxRows: 100, maxBytes: 1})
+	if p.RowCount != 1 {
+		t.Errorf("page has %d rows, want 1", p.RowCount)
+	}
+}
+
+func TestResultStoreBounds(t *testing.T) {
+	store := newResultStore(2, 0, 50*time.Millisecond)
+	defer store.close()
+
+	first, _ := store.put(testResult(1), "")
+	time.Sleep(time.Millisecond)
+	second, _ := store.put(testResult(1), "")
+	time.Sleep(time.Millisecond)
+	third, _ := store.put(testResult(1), "")
+
+	if _, err := store.get(first, ""); !errors.Is(err, ErrResultNotFound) {
+		t.Errorf("least recently used result was not evicted")
+	}
+	if _, err := store.get(second, ""); err != nil {
+		t.Errorf("get(second) error = %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if _, err := store.get(third, ""); !errors.Is(err, ErrResultNotFound) {
+		t.Errorf("expired result is still available")
+	}
+}
+
+func TestResultStoreOwner(t *testing.T) {
+	store := newResultStore(2, 0, time.Minute)
+	defer store.close()
+
+	handle, err := store.put(testResult(1), "alice")
+	if err != nil {
+		t.Fatalf("put() error = %v", err)
+	}
+	if _, err := store.get(handle, "bob"); !errors.Is(err, ErrResultNotFound) {
+		t.Errorf("get() by another user error = %v, want ErrResultNotFound", err)
+	}
+	if _, err := store.get(handle, "alice"); err != nil {
+		t.Errorf("get() by owner error = %v", err)
+	}
+}
+
+func TestResultStoreByteBudget(t *testing.T) {
+	size := testResult(3).rowsSize()
+	store := newResultStore(10, 2*size, time.Minute)
+	defer store.close()
+
+	first, _ := store.put(testResult(3), "")
+	time.Sleep(time.Millisecond)
+	second, _ := store.put(testResult(3), "")
+	time.Sleep(time.Millisecond)
+	if _, err := store.get(first, ""); err != nil {
+		t.Fatalf("get(first) error = %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	third, _ := store.put(testResult(3), "")
+
+	// The budget holds two results, so the least recently used one is evicted
+	if store.has(second) {
+		t.Errorf("least recently used result was not evicted when the byte budget was exceeded")
+	}
+	if !store.has(first) || !store.has(third) {
+		t.Errorf("recently used results were evicted")
+	}
+
+	// Releasing a result returns its bytes to the budget
+	store.release(first)
+	if _, err := store.put(testResult(3), ""); err != nil {
+		t.Fatalf("put() after release error = %v", err)
+	}
+	if !store.has(third) {
+		t.Errorf("result evicted although the released one made room")
+	}
+
+	// A result larger than the whole budget is refused without evicting anything
+	if _, err := store.put(testResult(10), ""); err == nil {
+		t.Errorf("put() of a result over the byte budget succeeded")
+	}
+	if !store.has(third) {
+		t.Errorf("oversized result evicted buffered results")
+	}
+}
+
+func TestExecuteQueryPagedBufferBytes(t *testing.T) {
+	client := newRowsClient(t, 50)
+	// Rows [1] to [9] take 4 bytes each with their separator and [10] takes 5
+	client.config.ResultBufferBytes = 9*4 + 5
+
+	p, err := client.ExecuteQueryPaged(context.Background(), "SELECT id FROM t", 4)
+	if err != nil {
+		t.Fatalf("ExecuteQueryPaged() error = %v", err)
+	}
+	if p.TotalRows != 10 || !p.Truncated {
+		t.Errorf("ExecuteQueryPaged() buffered %d rows (truncated %v), want 10 truncated at the byte limit", p.TotalRows, p.Truncated)
+	}
+}
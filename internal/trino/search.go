@@ -0,0 +1,332 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// defaultSearchLimit is how many matches a metadata search returns by default
+	defaultSearchLimit = 50
+	// maxSearchLimit caps the matches a metadata search returns
+	maxSearchLimit = 500
+	// searchScanRows bounds the matches read from each metadata table. The metadata
+	// queries rank their rows like the final ranking, so the best matches are kept.
+	searchScanRows = 2000
+)
+
+// Kinds of metadata search matches
+const (
+	MatchTable  = "table"
+	MatchColumn = "column"
+)
+
+// MetadataSearch describes a search of table and column names and comments
+type MetadataSearch struct {
+	// Pattern is matched case-insensitively anywhere in a name or comment; * matches
+	// any characters and ? a single character
+	Pattern string
+	// Catalog and Schema restrict the search; every catalog but system is searched by default
+	Catalog string
+	Schema  string
+	// Kind restricts the matches to MatchTable or MatchColumn; both are returned by default
+	Kind  string
+	Limit int
+}
+
+// MetadataMatch is a table or column matching a metadata search
+type MetadataMatch struct {
+	Kind    string `json:"kind"`
+	Catalog string `json:"catalog"`
+	Schema  string `json:"schema"`
+	Table   string `json:"table"`
+	Column  string `json:"column,omitempty"`
+	// Type is the data type of a column, or the type of a table, e.g. BASE TABLE or VIEW
+	Type    string `json:"type,omitempty"`
+	Comment string `json:"comment,omitempty"`
+	// MatchedOn is "name" or "comment"
+	MatchedOn string `json:"matched_on"`
+	// Score ranks the match: exact names first, then name prefixes, names containing
+	// the pattern and comments containing it
+	Score int `json:"score"`
+}
+
+// MetadataSearchResult holds the best matches of a metadata search
+type MetadataSearchResult struct {
+	Matches []MetadataMatch `json:"matches"`
+	// Truncated is set when more matches were found than returned
+	Truncated bool `json:"truncated"`
+	// Warnings lists catalogs that could not be searched completely
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// searchPattern is a search pattern as LIKE patterns for the metadata queries and as
+// regular expressions to rank the matches
+type searchPattern struct {
+	// body is the pattern in LIKE syntax; like matches it anywhere
+	body     string
+	like     string
+	exact    *regexp.Regexp
+	prefix   *regexp.Regexp
+	contains *regexp.Regexp
+}
+
+func newSearchPattern(pattern string) (*searchPattern, error) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if strings.Trim(pattern, "*?") == "" {
+		return nil, fmt.Errorf("search pattern must contain something other than wildcards")
+	}
+	var like, re strings.Builder
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			like.WriteByte('%')
+			re.WriteString(".*")
+		case '?':
+			like.WriteByte('_')
+			re.WriteString(".")
+		case '%', '_', '\\':
+			like.WriteByte('\\')
+			like.WriteRune(r)
+			re.WriteString(regexp.QuoteMeta(string(r)))
+		default:
+			like.WriteRune(r)
+			re.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	body := re.String()
+	return &searchPattern{
+		body:     like.String(),
+		like:     "%" + like.String() + "%",
+		exact:    regexp.MustCompile("(?s)^(?:" + body + ")$"),
+		prefix:   regexp.MustCompile("(?s)^(?:" + body + ")"),
+		contains: regexp.MustCompile("(?s)" + body),
+	}, nil
+}
+
+// condition matches a column against the pattern in SQL
+func (p *searchPattern) condition(column string) string {
+	return "lower(" + column + ") LIKE " + quoteLiteral(p.like) + " ESCAPE '\\'"
+}
+
+// rank orders rows in SQL the way score ranks them: exact names, name prefixes, names
+// containing the pattern and then the rest, which match on their comment
+func (p *searchPattern) rank(column string) string {
+	column = "lower(" + column + ")"
+	return "CASE WHEN " + column + " LIKE " + quoteLiteral(p.body) + " ESCAPE '\\' THEN 0" +
+		" WHEN " + column + " LIKE " + quoteLiteral(p.body+"%") + " ESCAPE '\\' THEN 1" +
+		" WHEN " + column + " LIKE " + quoteLiteral(p.like) + " ESCAPE '\\' THEN 2 ELSE 3 END"
+}
+
+// score ranks a name and comment against the pattern, returning 0 when neither matches
+func (p *searchPattern) score(name, comment string) (int, string) {
+	name = strings.ToLower(name)
+	switch {
+	case p.exact.MatchString(name):
+		return 100, "name"
+	case p.prefix.MatchString(name):
+		return 75, "name"
+	case p.contains.MatchString(name):
+		return 50, "name"
+	case p.contains.MatchString(strings.ToLower(comment)):
+		return 25, "comment"
+	}
+	return 0, ""
+}
+
+// SearchMetadata finds tables and columns whose names or comments match a pattern,
+// using information_schema and system.metadata.table_comments. Catalogs that cannot be
+// searched are reported as warnings unless a single catalog was requested.
+func (c *Client) SearchMetadata(ctx context.Context, search MetadataSearch) (*MetadataSearchResult, error) {
+	pattern, err := newSearchPattern(search.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	switch search.Kind {
+	case "", MatchTable, MatchColumn:
+	default:
+		return nil, fmt.Errorf("invalid kind %q: must be %s or %s", search.Kind, MatchTable, MatchColumn)
+	}
+	limit := search.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	schema := ""
+	if search.Schema != "" {
+		if schema, err = parseIdentifier(search.Schema); err != nil {
+			return nil, err
+		}
+	}
+
+	var catalogs []string
+	if search.Catalog != "" {
+		catalog, err := parseIdentifier(search.Catalog)
+		if err != nil {
+			return nil, err
+		}
+		catalogs = []string{catalog}
+	} else {
+		all, err := c.ListCatalogs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, catalog := range all {
+			// The system catalog describes the cluster, not data
+			if catalog != "system" {
+				catalogs = append(catalogs, catalog)
+			}
+		}
+	}
+
+	result := &MetadataSearchResult{Matches: []MetadataMatch{}}
+	for _, catalog := range catalogs {
+		matches, truncated, err := c.searchCatalog(ctx, catalog, schema, search.Kind, pattern)
+		if err != nil {
+			if search.Catalog != "" {
+				return nil, err
+			}
+			result.Warnings = append(result.Warnings, fmt.Sprintf("catalog %s was not searched: %v", catalog, err))
+			continue
+		}
+		if truncated {
+			result.Truncated = true
+			result.Warnings = append(result.Warnings, fmt.Sprintf("catalog %s has more than %d matches; "+
+				"use a more specific pattern or restrict the search to a schema", catalog, searchScanRows))
+		}
+		result.Matches = append(result.Matches, matches...)
+	}
+
+	sort.SliceStable(result.Matches, func(i, j int) bool {
+		a, b := result.Matches[i], result.Matches[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Kind != b.Kind {
+			return a.Kind == MatchTable
+		}
+		if a.Catalog != b.Catalog {
+			return a.Catalog < b.Catalog
+		}
+		if a.Schema != b.Schema {
+			return a.Schema < b.Schema
+		}
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		return a.Column < b.Column
+	})
+	if len(result.Matches) > limit {
+		result.Matches = result.Matches[:limit]
+		result.Truncated = true
+	}
+	return result, nil
+}
+
+// searchCatalog finds the tables and columns of one catalog matching the pattern. The
+// table comments are best effort, as connectors may not support them.
+func (c *Client) searchCatalog(ctx context.Context, catalog, schema, kind string, pattern *searchPattern) ([]MetadataMatch, bool, error) {
+	filter := "table_schema <> 'information_schema'"
+	if schema != "" {
+		filter += " AND table_schema = " + quoteLiteral(schema)
+	}
+	limits := resultLimits{maxRows: searchScanRows}
+	var matches []MetadataMatch
+	truncated := false
+
+	if kind != MatchColumn {
+		tables := make(map[string]*MetadataMatch)
+		var order []string
+		add := func(schema, table, tableType, comment string) {
+			key := schema + "." + table
+			m, ok := tables[key]
+			if !ok {
+				m = &MetadataMatch{Kind: MatchTable, Catalog: catalog, Schema: schema, Table: table}
+				tables[key] = m
+				order = append(order, key)
+			}
+			if tableType != "" {
+				m.Type = tableType
+			}
+			if comment != "" {
+				m.Comment = comment
+			}
+		}
+
+		query := "SELECT table_schema, table_name, table_type FROM " + QuoteIdentifier(catalog) +
+			".information_schema.tables WHERE " + filter + " AND " + pattern.condition("table_name") +
+			" ORDER BY " + pattern.rank("table_name")
+		result, err := c.execute(ctx, query, limits)
+		if err != nil {
+			return nil, false, err
+		}
+		truncated = truncated || result.Truncated
+		for _, row := range result.Rows {
+			add(rowString(row, 0), rowString(row, 1), rowString(row, 2), "")
+		}
+
+		query = "SELECT schema_name, table_name, comment FROM system.metadata.table_comments " +
+			"WHERE catalog_name = " + quoteLiteral(catalog)
+		if schema != "" {
+			query += " AND schema_name = " + quoteLiteral(schema)
+		}
+		query += " AND " + pattern.condition("comment")
+		if result, err := c.execute(ctx, query, limits); err == nil {
+			truncated = truncated || result.Truncated
+			for _, row := range result.Rows {
+				add(rowString(row, 0), rowString(row, 1), "", rowString(row, 2))
+			}
+		}
+
+		for _, key := range order {
+			m := tables[key]
+			if m.Score, m.MatchedOn = pattern.score(m.Table, m.Comment); m.Score > 0 {
+				// A table ranks above a column matching the same way
+				m.Score += 5
+				matches = append(matches, *m)
+			}
+		}
+	}
+
+	if kind != MatchTable {
+		query := "SELECT table_schema, table_name, column_name, data_type, comment FROM " + QuoteIdentifier(catalog) +
+			".information_schema.columns WHERE " + filter +
+			" AND (" + pattern.condition("column_name") + " OR " + pattern.condition("comment") + ")" +
+			" ORDER BY " + pattern.rank("column_name")
+		result, err := c.execute(ctx, query, limits)
+		if err != nil {
+			return nil, false, err
+		}
+		truncated = truncated || result.Truncated
+		for _, row := range result.Rows {
+			m := MetadataMatch{
+				Kind:    MatchColumn,
+				Catalog: catalog,
+				Schema:  rowString(row, 0),
+				Table:   rowString(row, 1),
+				Column:  rowString(row, 2),
+				Type:    rowString(row, 3),
+				Comment: rowString(row, 4),
+			}
+			if m.Score, m.MatchedOn = pattern.score(m.Column, m.Comment); m.Score > 0 {
+				matches = append(matches, m)
+			}
+		}
+	}
+	return matches, truncated, nil
+}
+
+// rowString returns the string value at a position of a row, or an empty string for
+// NULL or a missing column
+func rowString(row []interface{}, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	s, _ := row[i].(string)
+	return s
+}
@@ -0,0 +1,173 @@
+package trino
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+// metadataServer is a coordinator whose information_schema holds a few tables and
+// columns, some of which match "email". The catalog "broken" cannot be searched.
+func metadataServer() *trinotest.Server {
+	srv := trinotest.NewServer()
+	varchar := func(names ...string) []trinotest.Column {
+		columns := make([]trinotest.Column, len(names))
+		for i, name := range names {
+			columns[i] = trinotest.Column{Name: name, Type: "varchar"}
+		}
+		return columns
+	}
+	tables := varchar("table_schema", "table_name", "table_type")
+	comments := varchar("schema_name", "table_name", "comment")
+	columns := varchar("table_schema", "table_name", "column_name", "data_type", "comment")
+	srv.HandleFunc(func(sql string) (trinotest.Result, bool) {
+		switch {
+		case sql == "SHOW CATALOGS":
+			return trinotest.Result{Columns: varchar("Catalog"),
+				Rows: [][]interface{}{{"hive"}, {"system"}, {"tpch"}, {"broken"}}}, true
+		case strings.HasPrefix(sql, `SELECT table_schema, table_name, table_type FROM "tpch".`):
+			return trinotest.Result{Columns: tables, Rows: [][]interface{}{
+				{"tiny", "customer_email_view", "VIEW"},
+				{"tiny", "customer", "BASE TABLE"},
+			}}, true
+		case strings.Contains(sql, "table_comments WHERE catalog_name = 'tpch'"):
+			return trinotest.Result{Columns: comments, Rows: [][]interface{}{
+				{"tiny", "contacts", "Customer email addresses"},
+			}}, true
+		case strings.HasPrefix(sql, `SELECT table_schema, table_name, column_name, data_type, comment FROM "tpch".`):
+			return trinotest.Result{Columns: columns, Rows: [][]interface{}{
+				{"tiny", "orders", "comment", "varchar", "Contact email of the customer"},
+				{"tiny", "customer", "email", "varchar", nil},
+				{"tiny", "orders", "orderkey", "bigint", nil},
+			}}, true
+		case strings.HasPrefix(sql, `SELECT table_schema, table_name, column_name, data_type, comment FROM "hive".`):
+			return trinotest.Result{Columns: columns, Rows: [][]interface{}{
+				{"web", "users", "email_address", "varchar", nil},
+			}}, true
+		case strings.Contains(sql, `"broken"`):
+			return trinotest.Result{Error: "Catalog 'broken' is unavailable"}, true
+		}
+		return trinotest.Result{Columns: tables}, true
+	})
+	return srv
+}
+
+func TestSearchMetadata(t *testing.T) {
+	srv := metadataServer()
+	defer srv.Close()
+	client, err := NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	result, err := client.SearchMetadata(ctx, MetadataSearch{Pattern: "Email"})
+	if err != nil {
+		t.Fatalf("SearchMetadata() error = %v", err)
+	}
+	want := []struct {
+		kind, name, matchedOn string
+		score                 int
+	}{
+		{MatchColumn, "tpch.tiny.customer.email", "name", 100},
+		{MatchColumn, "hive.web.users.email_address", "name", 75},
+		{MatchTable, "tpch.tiny.customer_email_view", "name", 55},
+		{MatchTable, "tpch.tiny.contacts", "comment", 30},
+		{MatchColumn, "tpch.tiny.orders.comment", "comment", 25},
+	}
+	if len(result.Matches) != len(want) {
+		t.Fatalf("SearchMetadata() = %+v, want %d matches", result.Matches, len(want))
+	}
+	for i, w := range want {
+		m := result.Matches[i]
+		name := strings.Join([]string{m.Catalog, m.Schema, m.Table}, ".")
+		if m.Column != "" {
+			name += "." + m.Column
+		}
+		if m.Kind != w.kind || name != w.name || m.MatchedOn != w.matchedOn || m.Score != w.score {
+			t.Errorf("match %d = %s %s on %s scored %d, want %s %s on %s scored %d",
+				i, m.Kind, name, m.MatchedOn, m.Score, w.kind, w.name, w.matchedOn, w.score)
+		}
+	}
+	if result.Truncated {
+		t.Error("Truncated = true, want false")
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "broken") {
+		t.Errorf("Warnings = %q, want one for the broken catalog", result.Warnings)
+	}
+	for _, r := range srv.Requests() {
+		if strings.Contains(r.SQL, `"system"`) {
+			t.Errorf("searched the system catalog: %s", r.SQL)
+		}
+		// The scans are bounded, so they return exact names and prefixes first
+		if strings.Contains(r.SQL, "information_schema") &&
+			!strings.Contains(r.SQL, "ORDER BY CASE WHEN lower(") {
+			t.Errorf("metadata scan is not ranked: %s", r.SQL)
+		}
+	}
+
+	// A limit keeps the best matches
+	result, err = client.SearchMetadata(ctx, MetadataSearch{Pattern: "email", Catalog: "tpch", Kind: MatchColumn, Limit: 1})
+	if err != nil {
+		t.Fatalf("SearchMetadata(limit 1) error = %v", err)
+	}
+	if len(result.Matches) != 1 || result.Matches[0].Column != "email" || !result.Truncated {
+		t.Errorf("SearchMetadata(limit 1) = %+v, want the email column, truncated", result)
+	}
+
+	// A single catalog that cannot be searched fails the search
+	if _, err := client.SearchMetadata(ctx, MetadataSearch{Pattern: "email", Catalog: "broken"}); err == nil {
+		t.Error("SearchMetadata(broken) succeeded, want error")
+	}
+	for _, search := range []MetadataSearch{
+		{Pattern: "*"},
+		{Pattern: " "},
+		{Pattern: "email", Kind: "view"},
+		{Pattern: "email", Catalog: "a.b"},
+	} {
+		if _, err := client.SearchMetadata(ctx, search); err == nil {
+			t.Errorf("SearchMetadata(%+v) succeeded, want error", search)
+		}
+	}
+}
+
+func TestSearchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		like    string
+		name    string
+		score   int
+	}{
+		{pattern: "email", like: "%email%", name: "EMAIL", score: 100},
+		{pattern: "email", like: "%email%", name: "email_address", score: 75},
+		{pattern: "email", like: "%email%", name: "customer_email", score: 50},
+		{pattern: "e_mail", like: `%e\_mail%`, name: "e-mail", score: 0},
+		{pattern: "50%", like: `%50\%%`, name: "discount_50%", score: 50},
+		{pattern: "cust*id", like: "%cust%id%", name: "customer_id", score: 100},
+		{pattern: "ord?r", like: "%ord_r%", name: "orders", score: 75},
+		{pattern: "a.b", like: "%a.b%", name: "axb", score: 0},
+	}
+	for _, tt := range tests {
+		p, err := newSearchPattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("newSearchPattern(%q) error = %v", tt.pattern, err)
+		}
+		if p.like != tt.like {
+			t.Errorf("newSearchPattern(%q).like = %q, want %q", tt.pattern, p.like, tt.like)
+		}
+		if score, _ := p.score(tt.name, ""); score != tt.score {
+			t.Errorf("pattern %q scored %q at %d, want %d", tt.pattern, tt.name, score, tt.score)
+		}
+	}
+
+	p, _ := newSearchPattern("e_mail")
+	want := `CASE WHEN lower(name) LIKE 'e\_mail' ESCAPE '\' THEN 0` +
+		` WHEN lower(name) LIKE 'e\_mail%' ESCAPE '\' THEN 1` +
+		` WHEN lower(name) LIKE '%e\_mail%' ESCAPE '\' THEN 2 ELSE 3 END`
+	if got := p.rank("name"); got != want {
+		t.Errorf("rank() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+package trino
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// abortWait bounds how long Shutdown waits for aborted queries to be cancelled
+const abortWait = 5 * time.Second
+
+// drain refuses new queries and waits for the running ones until ctx is done. Queries
+// still running then are cancelled, which cancels them on the coordinator, and logged.
+// It returns the number of aborted queries.
+func (q *inflightQueries) drain(ctx context.Context, cluster string) int {
+	q.mu.Lock()
+	q.closing = true
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return 0
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	aborted := len(q.queries)
+	for _, r := range q.queries {
+		log.Printf("Aborting query on cluster %s after %s: %s", cluster, time.Since(r.started).Round(time.Millisecond), r.query)
+		r.cancel()
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-time.After(abortWait):
+		log.Printf("WARNING: Aborted queries on cluster %s did not stop within %s", cluster, abortWait)
+	}
+	return aborted
+}
+
+// Shutdown stops accepting queries, waits for running ones until ctx is done, cancels
+// the rest and closes the client
+func (c *Client) Shutdown(ctx context.Context, cluster string) error {
+	if aborted := c.inflight.drain(ctx, cluster); aborted > 0 {
+		log.Printf("Aborted %d queries on cluster %s at shutdown", aborted, cluster)
+	}
+	return c.Close()
+}
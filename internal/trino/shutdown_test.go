@@ -0,0 +1,99 @@
+package trino
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+func TestShutdown(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	srv.SetResult("SELECT * FROM huge", trinotest.Result{Block: true})
+
+	client, err := NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	queryErr := make(chan error, 1)
+	go func() {
+		_, err := client.ExecuteQuery(context.Background(), "SELECT * FROM huge")
+		queryErr <- err
+	}()
+	// Wait for the query to reach the coordinator
+	deadline := time.Now().Add(2 * time.Second)
+	for len(srv.Requests()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := client.Shutdown(ctx, "default"); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	select {
+	case err := <-queryErr:
+		if err == nil {
+			t.Error("ExecuteQuery() succeeded, want an error after the grace period")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ExecuteQuery() did not return after Shutdown")
+	}
+	requests := srv.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("server received %d statements, want 1", len(requests))
+	}
+	deadline = time.Now().Add(2 * time.Second)
+	for !contains(srv.Cancelled(), requests[0].QueryID) {
+		if time.Now().After(deadline) {
+			t.Fatalf("query %s was not cancelled on the coordinator (cancelled: %v)", requests[0].QueryID, srv.Cancelled())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := client.ExecuteQuery(context.Background(), "SELECT 1"); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("ExecuteQuery() after Shutdown error = %v, want ErrShuttingDown", err)
+	}
+}
+
+func TestShutdownWithoutQueries(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	srv.SetResult("SELECT 1", trinotest.Result{
+		Columns: []trinotest.Column{{Name: "_col0", Type: "integer"}},
+		Rows:    [][]interface{}{{1}},
+	})
+
+	client, err := NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := client.ExecuteQuery(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("ExecuteQuery() error = %v", err)
+	}
+
+	start := time.Now()
+	if err := client.Shutdown(context.Background(), "default"); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown() took %v with no running queries", elapsed)
+	}
+	if len(srv.Cancelled()) != 0 {
+		t.Errorf("Shutdown() cancelled %v, want no cancellations", srv.Cancelled())
+	}
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,524 @@
+package trino
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// StatementKind is the broad category of a SQL statement
+type StatementKind string
+
+const (
+	// StatementUnknown is a statement the classifier does not recognize
+	StatementUnknown StatementKind = "unknown"
+	// StatementQuery is a read-only statement: SELECT, WITH, VALUES, TABLE, SHOW, DESCRIBE and EXPLAIN
+	StatementQuery StatementKind = "query"
+	// StatementDDL changes metadata or privileges: CREATE, DROP, ALTER, COMMENT, ANALYZE, GRANT, REVOKE, DENY
+	StatementDDL StatementKind = "ddl"
+	// StatementDML changes data: INSERT, UPDATE, DELETE, MERGE, TRUNCATE and REFRESH MATERIALIZED VIEW
+	StatementDML StatementKind = "dml"
+	// StatementSession changes connection state: SET, RESET, USE, PREPARE, DEALLOCATE and transaction control
+	StatementSession StatementKind = "session"
+	// StatementProcedure runs code the classifier cannot see into: CALL and EXECUTE
+	StatementProcedure StatementKind = "procedure"
+	// StatementExplainAnalyze executes the explained statement to collect runtime statistics
+	StatementExplainAnalyze StatementKind = "explain_analyze"
+)
+
+// Statement describes a classified SQL statement
+type Statement struct {
+	Kind StatementKind
+	// Verb is the normalized leading keyword(s), e.g. "SELECT", "CREATE TABLE" or "SET SESSION"
+	Verb string
+	// Target is the object a DDL, DML or procedure statement acts on, as written in the
+	// statement (one to three parts). Unquoted parts are lower-cased. Nil for queries.
+	Target []string
+	// TargetType is the type of Target: "table", "schema" or "procedure"
+	TargetType string
+	// Destination is the new name of an object renamed with ALTER ... RENAME TO, of the
+	// same type as Target; nil otherwise
+	Destination []string
+	// FromQuery is set for INSERT and CREATE TABLE statements whose rows come from a
+	// query, such as INSERT INTO ... SELECT and CREATE TABLE ... AS SELECT
+	FromQuery bool
+	// Inner is the explained statement for EXPLAIN ANALYZE, nil otherwise
+	Inner *Statement
+}
+
+// ReadOnly reports whether the statement can be run without changing any state
+func (s Statement) ReadOnly() bool {
+	switch s.Kind {
+	case StatementQuery:
+		return true
+	case StatementExplainAnalyze:
+		return s.Inner != nil && s.Inner.ReadOnly()
+	default:
+		return false
+	}
+}
+
+// String returns a short human readable description such as "ddl (DROP TABLE)"
+func (s Statement) String() string {
+	if s.Verb == "" {
+		return string(s.Kind)
+	}
+	return fmt.Sprintf("%s (%s)", s.Kind, s.Verb)
+}
+
+// Errors returned by ClassifyStatement
+var (
+	ErrEmptyStatement     = errors.New("statement is empty")
+	ErrMultipleStatements = errors.New("multiple statements are not allowed")
+)
+
+// ClassifyStatement lexes a single SQL statement and determines its kind.
+// String literals, quoted identifiers and comments are understood, so keywords
+// appearing inside them do not affect the result.
+func ClassifyStatement(query string) (Statement, error) {
+	tokens, err := lexSQL(query)
+	if err != nil {
+		return Statement{}, err
+	}
+
+	// Split on semicolons; a single trailing terminator is tolerated
+	for i, tok := range tokens {
+		if tok.kind == tokenSymbol && tok.text == ";" {
+			for _, rest := range tokens[i+1:] {
+				if !(rest.kind == tokenSymbol && rest.text == ";") {
+					return Statement{}, ErrMultipleStatements
+				}
+			}
+			tokens = tokens[:i]
+			break
+		}
+	}
+	if len(tokens) == 0 {
+		return Statement{}, ErrEmptyStatement
+	}
+
+	stmt := classifyTokens(tokens)
+
+	// A data-modifying statement nested inside a query, e.g. "SELECT * FROM (DELETE ...)",
+	// is not valid Trino SQL, but treat it as the write it pretends to be rather than a query
+	if stmt.Kind == StatementQuery {
+		for i := 1; i < len(tokens); i++ {
+			if tokens[i-1].kind != tokenSymbol || tokens[i-1].text != "(" {
+				continue
+			}
+			if looksLikeWrite(tokens[i:]) {
+				return classifyTokens(tokens[i:]), nil
+			}
+		}
+	}
+
+	return stmt, nil
+}
+
+// classifyTokens determines the statement kind from its leading keywords
+func classifyTokens(tokens []sqlToken) Statement {
+	// Skip leading parentheses: "(SELECT 1) UNION (SELECT 2)" is a query
+	for len(tokens) > 0 && tokens[0].kind == tokenSymbol && tokens[0].text == "(" {
+		tokens = tokens[1:]
+	}
+	if len(tokens) == 0 || tokens[0].kind != tokenWord {
+		return Statement{Kind: StatementUnknown}
+	}
+
+	first := tokens[0].keyword()
+	second := keywordAt(tokens, 1)
+
+	switch first {
+	case "SELECT", "WITH", "VALUES", "TABLE", "SHOW", "DESCRIBE":
+		return Statement{Kind: StatementQuery, Verb: first}
+	case "EXPLAIN":
+		return classifyExplain(tokens[1:])
+	case "INSERT", "MERGE":
+		stmt := withTarget(Statement{Kind: StatementDML, Verb: first}, targetTable, tokens[1:], "INTO")
+		if first == "INSERT" && len(stmt.Target) > 0 {
+			// Skip INSERT INTO and the name, which spans its parts and the dots between them
+			stmt.FromQuery = containsKeyword(tokens[2*len(stmt.Target)+1:], "SELECT", "TABLE")
+		}
+		return stmt
+	case "DELETE":
+		return withTarget(Statement{Kind: StatementDML, Verb: first}, targetTable, tokens[1:], "FROM")
+	case "UPDATE", "ANALYZE":
+		kind := StatementDML
+		if first == "ANALYZE" {
+			kind = StatementDDL
+		}
+		return withTarget(Statement{Kind: kind, Verb: first}, targetTable, tokens[1:])
+	case "TRUNCATE":
+		return withTarget(Statement{Kind: StatementDML, Verb: first}, targetTable, tokens[1:], "TABLE")
+	case "REFRESH":
+		return withTarget(Statement{Kind: StatementDML, Verb: joinKeywords(tokens, 3)}, targetTable, tokens[1:], "MATERIALIZED", "VIEW")
+	case "CREATE", "DROP", "ALTER":
+		return classifyObjectStatement(first, tokens[1:])
+	case "COMMENT":
+		stmt := Statement{Kind: StatementDDL, Verb: "COMMENT ON " + keywordAt(tokens, 2)}
+		if keywordAt(tokens, 1) != "ON" {
+			return stmt
+		}
+		stmt = withTarget(stmt, targetTable, tokens[3:])
+		if keywordAt(tokens, 2) == "COLUMN" && len(stmt.Target) > 1 {
+			// The last part of a column reference is the column itself
+			stmt.Target = stmt.Target[:len(stmt.Target)-1]
+		}
+		return stmt
+	case "GRANT", "REVOKE", "DENY":
+		stmt := Statement{Kind: StatementDDL, Verb: first}
+		for i, tok := range tokens {
+			if tok.kind == tokenWord && tok.keyword() == "ON" {
+				switch keywordAt(tokens, i+1) {
+				case "SCHEMA":
+					return withTarget(stmt, targetSchema, tokens[i+2:])
+				case "TABLE":
+					return withTarget(stmt, targetTable, tokens[i+2:])
+				default:
+					return withTarget(stmt, targetTable, tokens[i+1:])
+				}
+			}
+		}
+		return stmt
+	case "SET", "RESET":
+		return Statement{Kind: StatementSession, Verb: strings.TrimSpace(first + " " + second)}
+	case "USE", "PREPARE", "DEALLOCATE", "COMMIT", "ROLLBACK":
+		return Statement{Kind: StatementSession, Verb: first}
+	case "START":
+		return Statement{Kind: StatementSession, Verb: "START TRANSACTION"}
+	case "CALL":
+		return withTarget(Statement{Kind: StatementProcedure, Verb: first}, targetProcedure, tokens[1:])
+	case "EXECUTE":
+		if second == "IMMEDIATE" {
+			return Statement{Kind: StatementProcedure, Verb: "EXECUTE IMMEDIATE"}
+		}
+		return Statement{Kind: StatementProcedure, Verb: first}
+	default:
+		return Statement{Kind: StatementUnknown, Verb: first}
+	}
+}
+
+// classifyObjectStatement handles CREATE, DROP and ALTER, whose verb and target
+// depend on the object type that follows
+func classifyObjectStatement(verb string, tokens []sqlToken) Statement {
+	if keywordAt(tokens, 0) == "OR" && keywordAt(tokens, 1) == "REPLACE" {
+		tokens = tokens[2:]
+	}
+
+	object := keywordAt(tokens, 0)
+	stmt := Statement{Kind: StatementDDL, Verb: strings.TrimSpace(verb + " " + object)}
+	rest := tokens
+	if len(rest) > 0 {
+		rest = rest[1:]
+	}
+	if object == "MATERIALIZED" {
+		stmt.Verb = verb + " MATERIALIZED VIEW"
+		if len(rest) > 0 {
+			rest = rest[1:]
+		}
+	}
+
+	targetType := targetTable
+	switch object {
+	case "TABLE", "VIEW", "MATERIALIZED":
+	case "SCHEMA":
+		targetType = targetSchema
+	default:
+		// Roles, functions and other objects outside the catalog hierarchy have no target
+		return stmt
+	}
+
+	// Skip IF [NOT] EXISTS
+	if keywordAt(rest, 0) == "IF" {
+		rest = rest[1:]
+		if keywordAt(rest, 0) == "NOT" {
+			rest = rest[1:]
+		}
+		if keywordAt(rest, 0) == "EXISTS" {
+			rest = rest[1:]
+		}
+	}
+	stmt = withTarget(stmt, targetType, rest)
+	if len(stmt.Target) == 0 {
+		return stmt
+	}
+	// The name spans its parts and the dots between them
+	rest = rest[2*len(stmt.Target)-1:]
+	switch {
+	case stmt.Verb == "CREATE TABLE":
+		// CREATE TABLE ... [WITH (...)] AS query; AS within the column list or
+		// properties is nested in parentheses
+		depth := 0
+		for _, tok := range rest {
+			switch {
+			case tok.kind == tokenSymbol && tok.text == "(":
+				depth++
+			case tok.kind == tokenSymbol && tok.text == ")":
+				depth--
+			case depth == 0 && tok.kind == tokenWord && tok.keyword() == "AS":
+				stmt.FromQuery = true
+			}
+		}
+	case verb == "ALTER":
+		if keywordAt(rest, 0) == "RENAME" && keywordAt(rest, 1) == "TO" {
+			stmt.Destination = qualifiedNameAt(rest[2:])
+			// A schema is renamed within its catalog
+			if targetType == targetSchema && len(stmt.Destination) == 1 && len(stmt.Target) == 2 {
+				stmt.Destination = []string{stmt.Target[0], stmt.Destination[0]}
+			}
+		}
+	}
+	return stmt
+}
+
+// Target types recorded in Statement.TargetType
+const (
+	targetTable     = "table"
+	targetSchema    = "schema"
+	targetProcedure = "procedure"
+)
+
+// withTarget skips the expected keywords and parses the qualified name that follows
+func withTarget(stmt Statement, targetType string, tokens []sqlToken, expect ...string) Statement {
+	for _, kw := range expect {
+		if keywordAt(tokens, 0) != kw {
+			return stmt
+		}
+		tokens = tokens[1:]
+	}
+	if name := qualifiedNameAt(tokens); len(name) > 0 {
+		stmt.Target = name
+		stmt.TargetType = targetType
+	}
+	return stmt
+}
+
+// containsKeyword reports whether any of the keywords appears among the tokens
+func containsKeyword(tokens []sqlToken, keywords ...string) bool {
+	for _, tok := range tokens {
+		if tok.kind != tokenWord {
+			continue
+		}
+		for _, kw := range keywords {
+			if tok.keyword() == kw {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// qualifiedNameAt parses a dotted name such as catalog.schema."Table" at the start of tokens
+func qualifiedNameAt(tokens []sqlToken) []string {
+	var parts []string
+	for i := 0; i < len(tokens); i += 2 {
+		part, ok := tokens[i].identifier()
+		if !ok {
+			return nil
+		}
+		parts = append(parts, part)
+		if i+1 >= len(tokens) || tokens[i+1].kind != tokenSymbol || tokens[i+1].text != "." {
+			break
+		}
+	}
+	return parts
+}
+
+// looksLikeWrite reports whether the tokens start a data-modifying statement.
+// It is stricter than classifyTokens so that columns or functions named like
+// keywords (e.g. "upper(comment)") are not mistaken for statements.
+func looksLikeWrite(tokens []sqlToken) bool {
+	next := keywordAt(tokens, 1)
+	switch keywordAt(tokens, 0) {
+	case "INSERT", "MERGE":
+		return next == "INTO"
+	case "DELETE":
+		return next == "FROM"
+	case "TRUNCATE":
+		return next == "TABLE"
+	case "UPDATE":
+		return len(tokens) > 1 && (tokens[1].kind == tokenWord || tokens[1].kind == tokenQuotedIdentifier)
+	case "CREATE", "DROP", "ALTER":
+		switch next {
+		case "TABLE", "VIEW", "SCHEMA", "MATERIALIZED", "OR", "ROLE", "FUNCTION":
+			return true
+		}
+	}
+	return false
+}
+
+// classifyExplain handles the tokens following EXPLAIN. Plain EXPLAIN never runs the
+// statement and is a query; EXPLAIN ANALYZE runs it, so the inner kind is recorded.
+func classifyExplain(tokens []sqlToken) Statement {
+	// Skip an option list such as "(TYPE IO, FORMAT JSON)"
+	if len(tokens) > 0 && tokens[0].kind == tokenSymbol && tokens[0].text == "(" {
+		depth := 0
+		for i, tok := range tokens {
+			if tok.kind != tokenSymbol {
+				continue
+			}
+			if tok.text == "(" {
+				depth++
+			} else if tok.text == ")" {
+				depth--
+				if depth == 0 {
+					tokens = tokens[i+1:]
+					break
+				}
+			}
+		}
+	}
+
+	if keywordAt(tokens, 0) != "ANALYZE" {
+		return Statement{Kind: StatementQuery, Verb: "EXPLAIN"}
+	}
+	tokens = tokens[1:]
+	if keywordAt(tokens, 0) == "VERBOSE" {
+		tokens = tokens[1:]
+	}
+	inner := classifyTokens(tokens)
+	return Statement{Kind: StatementExplainAnalyze, Verb: "EXPLAIN ANALYZE", Inner: &inner}
+}
+
+// keywordAt returns the upper-cased word at position i, or "" if it is not a bare word
+func keywordAt(tokens []sqlToken, i int) string {
+	if i >= len(tokens) || tokens[i].kind != tokenWord {
+		return ""
+	}
+	return tokens[i].keyword()
+}
+
+// joinKeywords joins up to n leading bare words
+func joinKeywords(tokens []sqlToken, n int) string {
+	words := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		kw := keywordAt(tokens, i)
+		if kw == "" {
+			break
+		}
+		words = append(words, kw)
+	}
+	return strings.Join(words, " ")
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenQuotedIdentifier
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+type sqlToken struct {
+	kind tokenKind
+	text string
+}
+
+func (t sqlToken) keyword() string {
+	return strings.ToUpper(t.text)
+}
+
+// identifier returns the name an identifier token refers to: unquoted identifiers
+// are case-insensitive and folded to lower case, quoted ones are taken literally
+func (t sqlToken) identifier() (string, bool) {
+	switch t.kind {
+	case tokenWord:
+		return strings.ToLower(t.text), true
+	case tokenQuotedIdentifier:
+		quote := t.text[:1]
+		return strings.ReplaceAll(t.text[1:len(t.text)-1], quote+quote, quote), true
+	default:
+		return "", false
+	}
+}
+
+// lexSQL splits a statement into tokens following Trino's lexical rules.
+// Comments and whitespace are dropped.
+func lexSQL(query string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	i := 0
+	for i < len(query) {
+		c := query[i]
+		switch {
+		case isSpace(c):
+			i++
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			end := strings.IndexAny(query[i:], "\r\n")
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += end
+			}
+		case c == '/' && i+1 < len(query) && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return nil, errors.New("unterminated block comment")
+			}
+			i += end + 4
+		case c == '\'':
+			end, err := scanQuoted(query, i, '\'')
+			if err != nil {
+				return nil, errors.New("unterminated string literal")
+			}
+			tokens = append(tokens, sqlToken{kind: tokenString, text: query[i:end]})
+			i = end
+		case c == '"' || c == '`':
+			end, err := scanQuoted(query, i, c)
+			if err != nil {
+				return nil, errors.New("unterminated quoted identifier")
+			}
+			tokens = append(tokens, sqlToken{kind: tokenQuotedIdentifier, text: query[i:end]})
+			i = end
+		case isWordStart(c):
+			start := i
+			for i < len(query) && isWordPart(query[i]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenWord, text: query[start:i]})
+		case isDigit(c) || (c == '.' && i+1 < len(query) && isDigit(query[i+1])):
+			start := i
+			for i < len(query) && (isWordPart(query[i]) || query[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenNumber, text: query[start:i]})
+		default:
+			tokens = append(tokens, sqlToken{kind: tokenSymbol, text: string(c)})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// scanQuoted returns the index just past the closing quote of the quoted
+// section starting at start. A doubled quote is an escaped quote character.
+func scanQuoted(s string, start int, quote byte) (int, error) {
+	for i := start + 1; i < len(s); i++ {
+		if s[i] != quote {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1, nil
+	}
+	return 0, errors.New("unterminated")
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isWordPart(c byte) bool {
+	return isWordStart(c) || isDigit(c)
+}
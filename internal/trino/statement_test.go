@@ -0,0 +1,149 @@
+package trino
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestClassifyStatement(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		kind     StatementKind
+		verb     string
+		readOnly bool
+	}{
+		{"SELECT", "SELECT * FROM users", StatementQuery, "SELECT", true},
+		{"Parenthesized query", "(SELECT 1) UNION ALL (SELECT 2)", StatementQuery, "SELECT", true},
+		{"VALUES", "VALUES (1, 'a')", StatementQuery, "VALUES", true},
+		{"SHOW", "SHOW CATALOGS", StatementQuery, "SHOW", true},
+		{"EXPLAIN with options", "EXPLAIN (TYPE IO, FORMAT JSON) SELECT * FROM users", StatementQuery, "EXPLAIN", true},
+		{"EXPLAIN of a write is not executed", "EXPLAIN INSERT INTO users VALUES (1)", StatementQuery, "EXPLAIN", true},
+		{"EXPLAIN ANALYZE query", "EXPLAIN ANALYZE VERBOSE SELECT * FROM users", StatementExplainAnalyze, "EXPLAIN ANALYZE", true},
+		{"EXPLAIN ANALYZE write", "EXPLAIN ANALYZE DELETE FROM users", StatementExplainAnalyze, "EXPLAIN ANALYZE", false},
+		{"INSERT", "INSERT INTO users VALUES (1)", StatementDML, "INSERT", false},
+		{"MERGE", "MERGE INTO t USING s ON t.id = s.id WHEN MATCHED THEN DELETE", StatementDML, "MERGE", false},
+		{"REFRESH MATERIALIZED VIEW", "REFRESH MATERIALIZED VIEW mv", StatementDML, "REFRESH MATERIALIZED VIEW", false},
+		{"CREATE TABLE AS", "CREATE TABLE scratch.t AS SELECT 1 AS x", StatementDDL, "CREATE TABLE", false},
+		{"CREATE OR REPLACE VIEW", "create or replace view v as select 1", StatementDDL, "CREATE VIEW", false},
+		{"DROP MATERIALIZED VIEW", "DROP MATERIALIZED VIEW mv", StatementDDL, "DROP MATERIALIZED VIEW", false},
+		{"COMMENT ON", "COMMENT ON TABLE users IS 'people'", StatementDDL, "COMMENT ON TABLE", false},
+		{"GRANT", "GRANT SELECT ON users TO ROLE analyst", StatementDDL, "GRANT", false},
+		{"SET SESSION", "SET SESSION query_max_run_time = '1h'", StatementSession, "SET SESSION", false},
+		{"USE", "USE hive.default", StatementSession, "USE", false},
+		{"CALL", "CALL system.runtime.kill_query(query_id => '1')", StatementProcedure, "CALL", false},
+		{"EXECUTE", "EXECUTE stmt USING 1", StatementProcedure, "EXECUTE", false},
+		{"EXECUTE IMMEDIATE", "EXECUTE IMMEDIATE 'SELECT 1'", StatementProcedure, "EXECUTE IMMEDIATE", false},
+		{"Function named like a keyword", "SELECT upper(comment) FROM orders", StatementQuery, "SELECT", true},
+		{"Nested DELETE", "SELECT * FROM (DELETE FROM users)", StatementDML, "DELETE", false},
+		{"Unknown", "FROBNICATE users", StatementUnknown, "FROBNICATE", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := ClassifyStatement(tt.query)
+			if err != nil {
+				t.Fatalf("ClassifyStatement(%q) returned error: %v", tt.query, err)
+			}
+			if stmt.Kind != tt.kind || stmt.Verb != tt.verb {
+				t.Errorf("ClassifyStatement(%q) = %s, want %s (%s)", tt.query, stmt, tt.kind, tt.verb)
+			}
+			if stmt.ReadOnly() != tt.readOnly {
+				t.Errorf("ClassifyStatement(%q).ReadOnly() = %v, want %v", tt.query, stmt.ReadOnly(), tt.readOnly)
+			}
+		})
+	}
+}
+
+func TestClassifyStatementTarget(t *testing.T) {
+	tests := []struct {
+		query       string
+		target      string
+		targetType  string
+		destination string
+	}{
+		{"INSERT INTO hive.sales.orders SELECT 1", "hive.sales.orders", "table", ""},
+		{`DELETE FROM "Sales"."Order""s"`, `Sales.Order"s`, "table", ""},
+		{"UPDATE Orders SET x = 1", "orders", "table", ""},
+		{"CREATE TABLE IF NOT EXISTS scratch.t AS SELECT 1", "scratch.t", "table", ""},
+		{"CREATE OR REPLACE MATERIALIZED VIEW hive.s.mv AS SELECT 1", "hive.s.mv", "table", ""},
+		{"DROP SCHEMA IF EXISTS hive.scratch CASCADE", "hive.scratch", "schema", ""},
+		{"COMMENT ON COLUMN hive.s.t.c IS 'x'", "hive.s.t", "table", ""},
+		{"GRANT SELECT ON SCHEMA hive.s TO ROLE r", "hive.s", "schema", ""},
+		{"CALL system.runtime.kill_query('x')", "system.runtime.kill_query", "procedure", ""},
+		{"ALTER TABLE scratch.t RENAME TO prod.t", "scratch.t", "table", "prod.t"},
+		{"ALTER TABLE IF EXISTS t RENAME TO \"T2\"", "t", "table", "T2"},
+		{"ALTER MATERIALIZED VIEW hive.s.mv RENAME TO hive.p.mv", "hive.s.mv", "table", "hive.p.mv"},
+		{"ALTER SCHEMA hive.scratch RENAME TO prod", "hive.scratch", "schema", "hive.prod"},
+		{"ALTER TABLE hive.s.t RENAME COLUMN a TO b", "hive.s.t", "table", ""},
+		{"SELECT * FROM hive.sales.orders", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			stmt, err := ClassifyStatement(tt.query)
+			if err != nil {
+				t.Fatalf("ClassifyStatement(%q) returned error: %v", tt.query, err)
+			}
+			if got := strings.Join(stmt.Target, "."); got != tt.target || stmt.TargetType != tt.targetType {
+				t.Errorf("ClassifyStatement(%q) target = %q (%s), want %q (%s)", tt.query, got, stmt.TargetType, tt.target, tt.targetType)
+			}
+			if got := strings.Join(stmt.Destination, "."); got != tt.destination {
+				t.Errorf("ClassifyStatement(%q) destination = %q, want %q", tt.query, got, tt.destination)
+			}
+		})
+	}
+}
+
+func TestClassifyStatementFromQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"INSERT INTO t SELECT * FROM s", true},
+		{"INSERT INTO hive.sales.t (a, b) SELECT a, b FROM s", true},
+		{"INSERT INTO t TABLE s", true},
+		{"INSERT INTO t VALUES (1, 'a')", false},
+		{"CREATE TABLE t AS SELECT * FROM s", true},
+		{"CREATE TABLE IF NOT EXISTS t WITH (format = 'ORC') AS (SELECT * FROM s)", true},
+		{"CREATE TABLE t (a bigint, b varchar) WITH (format = 'ORC')", false},
+		{"CREATE VIEW v AS SELECT * FROM s", false},
+		{"SELECT * FROM s", false},
+	}
+
+	for _, tt := range tests {
+		stmt, err := ClassifyStatement(tt.query)
+		if err != nil {
+			t.Fatalf("ClassifyStatement(%q) error = %v", tt.query, err)
+		}
+		if stmt.FromQuery != tt.want {
+			t.Errorf("ClassifyStatement(%q) from query = %v, want %v", tt.query, stmt.FromQuery, tt.want)
+		}
+	}
+}
+
+func TestClassifyStatementErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		err   error
+	}{
+		{"Empty", "  -- nothing here\n", ErrEmptyStatement},
+		{"Multiple statements", "SELECT 1; DROP TABLE users", ErrMultipleStatements},
+		{"Unterminated string", "SELECT 'abc", nil},
+		{"Unterminated comment", "SELECT 1 /* abc", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ClassifyStatement(tt.query)
+			if err == nil {
+				t.Fatalf("ClassifyStatement(%q) succeeded, want error", tt.query)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("ClassifyStatement(%q) error = %v, want %v", tt.query, err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package trino
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Optional parts of a table description, besides its columns
+const (
+	// IncludeComment adds the table comment
+	IncludeComment = "comment"
+	// IncludeDDL adds the CREATE statement and the table properties of its WITH clause
+	IncludeDDL = "ddl"
+	// IncludeProperties adds the $properties metadata table
+	IncludeProperties = "properties"
+	// IncludePartitions adds a sample of the $partitions metadata table
+	IncludePartitions = "partitions"
+)
+
+// partitionSampleRows is how many rows of the $partitions metadata table are returned
+const partitionSampleRows = 20
+
+// TableColumn is a column of a table, as described by DESCRIBE
+type TableColumn struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Extra   string `json:"extra,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// TableBucketing describes how a Hive table is bucketed
+type TableBucketing struct {
+	Columns []string `json:"columns"`
+	Count   int      `json:"count,omitempty"`
+}
+
+// TableDetails describes a table: its columns and, on request, its comment, CREATE
+// statement and the connector's metadata tables
+type TableDetails struct {
+	Catalog string        `json:"catalog"`
+	Schema  string        `json:"schema"`
+	Table   string        `json:"table"`
+	Comment string        `json:"comment,omitempty"`
+	Columns []TableColumn `json:"columns"`
+	// Partitioning lists the partition columns, or the partition transforms of an
+	// Iceberg table such as day(ts)
+	Partitioning []string        `json:"partitioning,omitempty"`
+	Bucketing    *TableBucketing `json:"bucketing,omitempty"`
+	// Format is the file format, e.g. ORC or PARQUET
+	Format string `json:"format,omitempty"`
+	// Properties are the table properties of the CREATE statement, as SQL expressions
+	Properties map[string]string `json:"properties,omitempty"`
+	// DDL is the CREATE statement; omitted if Trino does not return one
+	DDL string `json:"ddl,omitempty"`
+	// ConnectorProperties is the content of the $properties metadata table
+	ConnectorProperties map[string]interface{} `json:"connector_properties,omitempty"`
+	// Partitions holds the first rows of the $partitions metadata table; it is truncated
+	// when the table has more partitions
+	Partitions *QueryResult `json:"partitions,omitempty"`
+	// Warnings lists the requested parts that could not be read, e.g. because the
+	// connector has no such metadata table
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// DescribeTable describes a table's columns and the optional parts named in include.
+// The table is resolved like in GetTableSchema. Optional parts that cannot be read are
+// reported as warnings, as connectors support them to different degrees.
+func (c *Client) DescribeTable(ctx context.Context, catalog, schema, table string, include []string) (*TableDetails, error) {
+	parts := make(map[string]bool, len(include))
+	for _, part := range include {
+		switch part {
+		case IncludeComment, IncludeDDL, IncludeProperties, IncludePartitions:
+			parts[part] = true
+		default:
+			return nil, fmt.Errorf("invalid include %q: must be %s, %s, %s or %s",
+				part, IncludeComment, IncludeDDL, IncludeProperties, IncludePartitions)
+		}
+	}
+	name, err := c.tableName(catalog, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	// The resolved name is passed on quoted, so it is not parsed again
+	quoted := QuoteQualifiedName(name...)
+
+	description, err := c.GetTableSchema(ctx, "", "", quoted)
+	if err != nil {
+		return nil, err
+	}
+	details := &TableDetails{Catalog: name[0], Schema: name[1], Table: name[2], Columns: make([]TableColumn, 0, len(description.Rows))}
+	nameIdx, typeIdx := description.ColumnIndex("Column"), description.ColumnIndex("Type")
+	extraIdx, commentIdx := description.ColumnIndex("Extra"), description.ColumnIndex("Comment")
+	for _, row := range description.Rows {
+		column := TableColumn{
+			Name:    rowString(row, nameIdx),
+			Type:    rowString(row, typeIdx),
+			Extra:   rowString(row, extraIdx),
+			Comment: rowString(row, commentIdx),
+		}
+		details.Columns = append(details.Columns, column)
+		// Hive marks its partition columns
+		if strings.Contains(column.Extra, "partition key") {
+			details.Partitioning = append(details.Partitioning, column.Name)
+		}
+	}
+
+	if parts[IncludeComment] {
+		if details.Comment, err = c.GetTableComment(ctx, name[0], name[1], name[2]); err != nil {
+			details.Warnings = append(details.Warnings, "comment: "+err.Error())
+		}
+	}
+	if parts[IncludeDDL] {
+		if details.DDL, err = c.ShowCreateTable(ctx, "", "", quoted); err != nil {
+			details.Warnings = append(details.Warnings, "ddl: "+err.Error())
+		} else {
+			details.setProperties(tableProperties(details.DDL))
+		}
+	}
+	if parts[IncludeProperties] {
+		query := "SELECT * FROM " + QuoteQualifiedName(name[0], name[1], name[2]+"$properties")
+		if result, err := c.execute(ctx, query, resultLimits{maxRows: c.config.MaxRows}); err != nil {
+			details.Warnings = append(details.Warnings, "properties: "+err.Error())
+		} else {
+			details.ConnectorProperties = propertiesMap(result)
+		}
+	}
+	if parts[IncludePartitions] {
+		query := fmt.Sprintf("SELECT * FROM %s LIMIT %d",
+			QuoteQualifiedName(name[0], name[1], name[2]+"$partitions"), partitionSampleRows+1)
+		if result, err := c.execute(ctx, query, resultLimits{maxRows: partitionSampleRows}); err != nil {
+			details.Warnings = append(details.Warnings, "partitions: "+err.Error())
+		} else {
+			if result.Truncated {
+				result.Hint = fmt.Sprintf("Only the first %d partitions are shown. Query the $partitions table for the rest.",
+					partitionSampleRows)
+			}
+			details.Partitions = result
+		}
+	}
+	return details, nil
+}
+
+// setProperties records the table properties of the CREATE statement and the format,
+// partitioning and bucketing they describe. Hive and Delta Lake name the partition
+// columns in partitioned_by, Iceberg its partition transforms in partitioning.
+func (d *TableDetails) setProperties(properties map[string]string) {
+	if len(properties) == 0 {
+		return
+	}
+	d.Properties = properties
+	if format, ok := stringLiteral(properties["format"]); ok {
+		d.Format = format
+	}
+	if len(d.Partitioning) == 0 {
+		for _, key := range []string{"partitioned_by", "partitioning"} {
+			if columns, ok := stringArray(properties[key]); ok {
+				d.Partitioning = columns
+				break
+			}
+		}
+	}
+	if columns, ok := stringArray(properties["bucketed_by"]); ok && len(columns) > 0 {
+		d.Bucketing = &TableBucketing{Columns: columns}
+		d.Bucketing.Count, _ = strconv.Atoi(properties["bucket_count"])
+	}
+}
+
+// tableProperties returns the properties in the WITH clause of a CREATE statement,
+// mapped to their SQL expressions
+func tableProperties(ddl string) map[string]string {
+	tokens, err := lexSQL(ddl)
+	if err != nil {
+		return nil
+	}
+	depth := 0
+	for i, t := range tokens {
+		switch {
+		case t.text == "(":
+			depth++
+		case t.text == ")":
+			depth--
+		case depth == 0 && t.kind == tokenWord && t.keyword() == "AS":
+			// The query of a view or materialized view follows
+			return nil
+		case depth == 0 && t.kind == tokenWord && t.keyword() == "WITH" && i+1 < len(tokens) && tokens[i+1].text == "(":
+			return propertyList(tokens[i+2:])
+		}
+	}
+	return nil
+}
+
+// propertyList parses name = value pairs up to the closing parenthesis of the list
+func propertyList(tokens []sqlToken) map[string]string {
+	properties := make(map[string]string)
+	depth, start := 0, 0
+	for i := 0; i <= len(tokens); i++ {
+		end := i == len(tokens)
+		if !end {
+			switch tokens[i].text {
+			case "(", "[":
+				depth++
+				continue
+			case ")", "]":
+				if depth > 0 {
+					depth--
+					continue
+				}
+				end = true
+			case ",":
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		property := tokens[start:i]
+		if len(property) > 2 && property[1].text == "=" {
+			if name, ok := property[0].identifier(); ok {
+				properties[name] = joinTokens(property[2:])
+			}
+		}
+		if end {
+			break
+		}
+		start = i + 1
+	}
+	return properties
+}
+
+// joinTokens renders tokens as SQL, separating only the tokens that need it
+func joinTokens(tokens []sqlToken) string {
+	var b strings.Builder
+	for i, t := range tokens {
+		if i > 0 && t.kind != tokenSymbol && tokens[i-1].kind != tokenSymbol {
+			b.WriteByte(' ')
+		}
+		b.WriteString(t.text)
+	}
+	return b.String()
+}
+
+// stringLiteral returns the value of a SQL string literal
+func stringLiteral(expr string) (string, bool) {
+	if len(expr) < 2 || expr[0] != '\'' || expr[len(expr)-1] != '\'' {
+		return "", false
+	}
+	return strings.ReplaceAll(expr[1:len(expr)-1], "''", "'"), true
+}
+
+// stringArray returns the values of an ARRAY['a', 'b'] expression
+func stringArray(expr string) ([]string, bool) {
+	tokens, err := lexSQL(expr)
+	if err != nil || len(tokens) < 3 || tokens[0].keyword() != "ARRAY" || tokens[1].text != "[" || tokens[len(tokens)-1].text != "]" {
+		return nil, false
+	}
+	values := []string{}
+	for i, t := range tokens[2 : len(tokens)-1] {
+		if i%2 == 1 {
+			if t.text != "," {
+				return nil, false
+			}
+			continue
+		}
+		value, ok := stringLiteral(t.text)
+		if t.kind != tokenString || !ok {
+			return nil, false
+		}
+		values = append(values, value)
+	}
+	return values, true
+}
+
+// propertiesMap converts a $properties metadata table to a map. Iceberg lists the
+// properties as key and value rows, Hive as the columns of a single row.
+func propertiesMap(result *QueryResult) map[string]interface{} {
+	properties := make(map[string]interface{})
+	key, value := result.ColumnIndex("key"), result.ColumnIndex("value")
+	if len(result.Columns) == 2 && key >= 0 && value >= 0 {
+		for _, row := range result.Rows {
+			properties[rowString(row, key)] = row[value]
+		}
+		return properties
+	}
+	if len(result.Rows) > 0 {
+		for i, column := range result.Columns {
+			properties[column.Name] = result.Rows[0][i]
+		}
+	}
+	return properties
+}
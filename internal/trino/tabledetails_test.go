@@ -0,0 +1,198 @@
+package trino
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tuannvm/mcp-trino/internal/trinotest"
+)
+
+const hiveDDL = `CREATE TABLE hive.web.events (
+   id bigint,
+   payload row(kind varchar, "with" varchar),
+   ds varchar
+)
+COMMENT 'Events WITH (fake) properties'
+WITH (
+   bucket_count = 16,
+   bucketed_by = ARRAY['id'],
+   format = 'ORC',
+   partitioned_by = ARRAY['ds'],
+   "transactional" = false
+)`
+
+const icebergDDL = `CREATE TABLE iceberg.web.clicks (
+   ts timestamp(6) with time zone,
+   region varchar
+)
+WITH (
+   format = 'PARQUET',
+   location = 's3://bucket/it''s/clicks',
+   partitioning = ARRAY['day(ts)','region']
+)`
+
+func TestTableProperties(t *testing.T) {
+	tests := []struct {
+		name string
+		ddl  string
+		want map[string]string
+	}{
+		{
+			name: "hive",
+			ddl:  hiveDDL,
+			want: map[string]string{
+				"bucket_count":   "16",
+				"bucketed_by":    "ARRAY['id']",
+				"format":         "'ORC'",
+				"partitioned_by": "ARRAY['ds']",
+				"transactional":  "false",
+			},
+		},
+		{
+			name: "iceberg",
+			ddl:  icebergDDL,
+			want: map[string]string{
+				"format":       "'PARQUET'",
+				"location":     "'s3://bucket/it''s/clicks'",
+				"partitioning": "ARRAY['day(ts)','region']",
+			},
+		},
+		{name: "no properties", ddl: "CREATE TABLE memory.default.t (\n   id bigint\n)"},
+		{name: "view", ddl: "CREATE VIEW hive.web.v AS\nWITH x AS (SELECT 1 a) SELECT a FROM x"},
+		{name: "materialized view properties", ddl: "CREATE MATERIALIZED VIEW iceberg.web.mv\nWITH (\n   format = 'ORC'\n) AS\nSELECT 1 a",
+			want: map[string]string{"format": "'ORC'"}},
+		{name: "unterminated", ddl: "CREATE TABLE t (id bigint) WITH (format = 'ORC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tableProperties(tt.ddl)
+			if len(got) != len(tt.want) || (len(tt.want) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("tableProperties() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableDetailsProperties(t *testing.T) {
+	var hive TableDetails
+	hive.setProperties(tableProperties(hiveDDL))
+	if hive.Format != "ORC" || !reflect.DeepEqual(hive.Partitioning, []string{"ds"}) ||
+		hive.Bucketing == nil || !reflect.DeepEqual(hive.Bucketing.Columns, []string{"id"}) || hive.Bucketing.Count != 16 {
+		t.Errorf("hive details = format %q, partitioning %q, bucketing %+v", hive.Format, hive.Partitioning, hive.Bucketing)
+	}
+
+	var iceberg TableDetails
+	iceberg.setProperties(tableProperties(icebergDDL))
+	if iceberg.Format != "PARQUET" || !reflect.DeepEqual(iceberg.Partitioning, []string{"day(ts)", "region"}) || iceberg.Bucketing != nil {
+		t.Errorf("iceberg details = format %q, partitioning %q, bucketing %+v", iceberg.Format, iceberg.Partitioning, iceberg.Bucketing)
+	}
+
+	for _, expr := range []string{"'a'", "ARRAY['a',1]", "ARRAY['a' 'b']", "array('a')", "ARRAY['a'"} {
+		if values, ok := stringArray(expr); ok {
+			t.Errorf("stringArray(%s) = %q, want no string array", expr, values)
+		}
+	}
+	if values, ok := stringArray("ARRAY[]"); !ok || len(values) != 0 {
+		t.Errorf("stringArray(ARRAY[]) = %q, %v; want an empty array", values, ok)
+	}
+}
+
+func TestDescribeTable(t *testing.T) {
+	srv := trinotest.NewServer()
+	defer srv.Close()
+	varchar := func(names ...string) []trinotest.Column {
+		columns := make([]trinotest.Column, len(names))
+		for i, name := range names {
+			columns[i] = trinotest.Column{Name: name, Type: "varchar"}
+		}
+		return columns
+	}
+	srv.SetResult(`DESCRIBE "hive"."web"."events"`, trinotest.Result{
+		Columns: varchar("Column", "Type", "Extra", "Comment"),
+		Rows: [][]interface{}{
+			{"id", "bigint", "", "Event ID"},
+			{"ds", "varchar", "partition key", ""},
+		},
+	})
+	srv.SetResult(`SHOW CREATE TABLE "hive"."web"."events"`, trinotest.Result{
+		Columns: varchar("Create Table"),
+		Rows:    [][]interface{}{{hiveDDL}},
+	})
+	srv.SetResult("SELECT comment FROM system.metadata.table_comments "+
+		"WHERE catalog_name = 'hive' AND schema_name = 'web' AND table_name = 'events'", trinotest.Result{
+		Columns: varchar("comment"),
+		Rows:    [][]interface{}{{"Web events"}},
+	})
+	srv.SetResult(`SELECT * FROM "hive"."web"."events$properties"`, trinotest.Result{
+		Columns: varchar("numFiles", "transactional"),
+		Rows:    [][]interface{}{{"42", "false"}},
+	})
+	partitions := make([][]interface{}, 25)
+	for i := range partitions {
+		partitions[i] = []interface{}{"2024-01-01"}
+	}
+	srv.SetResult(`SELECT * FROM "hive"."web"."events$partitions" LIMIT 21`, trinotest.Result{
+		Columns: varchar("ds"),
+		Rows:    partitions,
+	})
+
+	client, err := NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	// Without includes, only DESCRIBE runs
+	details, err := client.DescribeTable(ctx, "hive", "web", "events", nil)
+	if err != nil {
+		t.Fatalf("DescribeTable() error = %v", err)
+	}
+	wantColumns := []TableColumn{
+		{Name: "id", Type: "bigint", Comment: "Event ID"},
+		{Name: "ds", Type: "varchar", Extra: "partition key"},
+	}
+	if !reflect.DeepEqual(details.Columns, wantColumns) || !reflect.DeepEqual(details.Partitioning, []string{"ds"}) {
+		t.Errorf("DescribeTable() columns = %+v, partitioning %q", details.Columns, details.Partitioning)
+	}
+	if details.DDL != "" || details.Comment != "" || details.Partitions != nil || len(srv.Requests()) != 1 {
+		t.Errorf("DescribeTable() without includes read %d statements: %+v", len(srv.Requests()), details)
+	}
+
+	details, err = client.DescribeTable(ctx, "", "", "hive.web.events",
+		[]string{IncludeComment, IncludeDDL, IncludeProperties, IncludePartitions})
+	if err != nil {
+		t.Fatalf("DescribeTable(all) error = %v", err)
+	}
+	if details.Comment != "Web events" || details.DDL != hiveDDL || details.Format != "ORC" || details.Bucketing == nil {
+		t.Errorf("DescribeTable(all) = comment %q, format %q, bucketing %+v", details.Comment, details.Format, details.Bucketing)
+	}
+	if details.Properties["partitioned_by"] != "ARRAY['ds']" || details.ConnectorProperties["numFiles"] != "42" {
+		t.Errorf("DescribeTable(all) properties = %q, connector properties %v", details.Properties, details.ConnectorProperties)
+	}
+	if p := details.Partitions; p == nil || len(p.Rows) != partitionSampleRows || !p.Truncated || !strings.Contains(p.Hint, "$partitions") {
+		t.Errorf("DescribeTable(all) partitions = %+v, want %d rows, truncated", p, partitionSampleRows)
+	}
+	if len(details.Warnings) != 0 {
+		t.Errorf("DescribeTable(all) warnings = %q, want none", details.Warnings)
+	}
+
+	// Metadata tables the connector lacks are reported as warnings
+	srv.SetResult(`DESCRIBE "memory"."default"."t"`, trinotest.Result{
+		Columns: varchar("Column", "Type", "Extra", "Comment"),
+		Rows:    [][]interface{}{{"id", "bigint", "", ""}},
+	})
+	details, err = client.DescribeTable(ctx, "", "", "t", []string{IncludePartitions})
+	if err != nil {
+		t.Fatalf("DescribeTable(memory) error = %v", err)
+	}
+	if len(details.Warnings) != 1 || !strings.HasPrefix(details.Warnings[0], "partitions: ") || details.Partitions != nil {
+		t.Errorf("DescribeTable(memory) warnings = %q, want one for partitions", details.Warnings)
+	}
+
+	if _, err := client.DescribeTable(ctx, "", "", "t", []string{"stats"}); err == nil {
+		t.Error("DescribeTable() with an unknown include succeeded, want error")
+	}
+}
@@ -0,0 +1,127 @@
+package trino
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/trinodb/trino-go-client/trino"
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// clientSeq numbers the HTTP clients registered with the driver
+var clientSeq atomic.Int64
+
+// registerHTTPClient builds the HTTP client for a cluster's TLS and token settings and
+// registers it with the driver. It returns the name the DSN refers to it by.
+func registerHTTPClient(cfg *config.TrinoConfig) (string, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if strings.EqualFold(cfg.Scheme, "https") {
+		minVersion, err := config.TLSVersion(cfg.TLSMinVersion)
+		if err != nil {
+			return "", err
+		}
+		tlsConfig := &tls.Config{
+			MinVersion:         minVersion,
+			ServerName:         cfg.TLSServerName,
+			InsecureSkipVerify: cfg.SSLInsecure,
+		}
+		if cfg.CAFile != "" {
+			pool, err := config.LoadCAFile(cfg.CAFile)
+			if err != nil {
+				return "", err
+			}
+			tlsConfig.RootCAs = pool
+		}
+		if cfg.ClientCert != "" {
+			cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+			if err != nil {
+				return "", fmt.Errorf("failed to load client certificate: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	var rt http.RoundTripper = transport
+	if cfg.Auth == config.AuthJWT {
+		source := &tokenSource{token: cfg.AccessToken, file: cfg.AccessTokenFile}
+		if _, err := source.Token(); err != nil {
+			return "", err
+		}
+		rt = &bearerTransport{next: transport, source: source}
+	}
+
+	name := "mcp-trino-" + strconv.FormatInt(clientSeq.Add(1), 10)
+	if err := trino.RegisterCustomClient(name, &http.Client{Transport: rt}); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// bearerTransport authenticates requests with the current access token
+type bearerTransport struct {
+	next   http.RoundTripper
+	source *tokenSource
+}
+
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.next.RoundTrip(req)
+}
+
+// tokenSource provides a static access token or one read from a file. The file is
+// re-read whenever it changes, so tokens refreshed by a sidecar are picked up without
+// a restart.
+type tokenSource struct {
+	token string
+	file  string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+// Token returns the current access token. If the file cannot be read after a token
+// was loaded, the last token is kept, since files are often replaced non-atomically.
+func (s *tokenSource) Token() (string, error) {
+	if s.file == "" {
+		return s.token, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.file)
+	if err == nil && s.token != "" && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.token, nil
+	}
+	var data []byte
+	if err == nil {
+		data, err = os.ReadFile(s.file)
+	}
+	token := strings.TrimSpace(string(data))
+	if err == nil && token == "" {
+		err = fmt.Errorf("access token file %s is empty", s.file)
+	}
+	if err != nil {
+		if s.token != "" {
+			log.Printf("WARNING: Failed to reload access token, using the previous one: %v", err)
+			return s.token, nil
+		}
+		return "", fmt.Errorf("failed to read access token: %w", err)
+	}
+	s.token, s.modTime, s.size = token, info.ModTime(), info.Size()
+	return s.token, nil
+}
@@ -0,0 +1,306 @@
+// Package trinotest provides an in-process stand-in for a Trino coordinator that
+// speaks enough of the client REST protocol for the trino-go-client driver.
+package trinotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuannvm/mcp-trino/internal/config"
+)
+
+// Column describes a result column. Type is a Trino type such as "varchar",
+// "decimal(38,2)", "array(bigint)" or "row(a bigint, b varchar)".
+type Column struct {
+	Name string
+	Type string
+}
+
+// Result is the canned response to a statement
+type Result struct {
+	Columns []Column
+	Rows    [][]interface{}
+	// Error, when set, fails the statement with this message
+	Error string
+	// Block keeps the statement running until it is cancelled
+	Block bool
+	// Delay keeps the statement running for this long before it returns its rows
+	Delay time.Duration
+}
+
+// Request is a statement received by the server
+type Request struct {
+	QueryID string
+	SQL     string
+	Header  http.Header
+}
+
+// Server is a fake Trino coordinator
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	results   map[string]Result
+	handler   func(sql string) (Result, bool)
+	requests  []Request
+	running   map[string]Result
+	started   map[string]time.Time
+	cancelled []string
+	nextID    int
+}
+
+// NewServer starts a fake coordinator; call Close when done
+func NewServer() *Server {
+	s := newServer()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewTLSServer starts a fake coordinator serving https with the self-signed
+// certificate of httptest, valid for 127.0.0.1 and example.com
+func NewTLSServer() *Server {
+	s := newServer()
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func newServer() *Server {
+	return &Server{
+		results: make(map[string]Result),
+		running: make(map[string]Result),
+		started: make(map[string]time.Time),
+	}
+}
+
+// SetResult registers the response for an exact statement text
+func (s *Server) SetResult(sql string, result Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[sql] = result
+}
+
+// HandleFunc registers a fallback used for statements without an exact result
+func (s *Server) HandleFunc(handler func(sql string) (Result, bool)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handler = handler
+}
+
+// Requests returns the statements received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Cancelled returns the IDs of queries cancelled with DELETE /v1/query/{id}
+func (s *Server) Cancelled() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.cancelled...)
+}
+
+// Config returns a client configuration pointing at the server
+func (s *Server) Config() *config.TrinoConfig {
+	u, _ := url.Parse(s.URL)
+	port, _ := strconv.Atoi(u.Port())
+	return &config.TrinoConfig{
+		Host:              u.Hostname(),
+		Port:              port,
+		User:              "test",
+		Catalog:           "memory",
+		Schema:            "default",
+		Scheme:            u.Scheme,
+		SSL:               u.Scheme == "https",
+		QueryTimeout:      10 * time.Second,
+		MaxRows:           1000,
+		MaxResultBytes:    1 << 20,
+		ResultBufferRows:  10000,
+		ResultBufferBytes: 1 << 20,
+		ResultStoreBytes:  4 << 20,
+		MaxResultHandles:  4,
+		ResultTTL:         time.Minute,
+		JobWorkers:        2,
+		JobQueueSize:      4,
+		JobTimeout:        time.Minute,
+		JobTTL:            time.Minute,
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/statement":
+		s.handleStatement(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/statement/executing/"):
+		s.handleNext(w, r)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/v1/query/"):
+		s.mu.Lock()
+		id := strings.TrimPrefix(r.URL.Path, "/v1/query/")
+		s.cancelled = append(s.cancelled, id)
+		delete(s.running, id)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) handleStatement(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sql := string(body)
+
+	s.mu.Lock()
+	s.nextID++
+	id := fmt.Sprintf("20240101_000000_%05d_fake0", s.nextID)
+	result, ok := s.results[sql]
+	if !ok && s.handler != nil {
+		result, ok = s.handler(sql)
+	}
+	if !ok {
+		result = Result{Error: fmt.Sprintf("trinotest: no result registered for %q", sql)}
+	}
+	s.running[id] = result
+	s.started[id] = time.Now()
+	s.requests = append(s.requests, Request{QueryID: id, SQL: sql, Header: r.Header.Clone()})
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]interface{}{
+		"id":      id,
+		"infoUri": s.URL + "/ui/query.html?" + id,
+		"nextUri": fmt.Sprintf("%s/v1/statement/executing/%s/1", s.URL, id),
+		"stats":   map[string]interface{}{"state": "QUEUED"},
+	})
+}
+
+func (s *Server) handleNext(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/statement/executing/"), "/")
+	id := parts[0]
+	token, _ := strconv.Atoi(parts[len(parts)-1])
+
+	s.mu.Lock()
+	result, ok := s.running[id]
+	started := s.started[id]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	resp := map[string]interface{}{"id": id}
+	switch {
+	case result.Block || time.Since(started) < result.Delay:
+		// Long poll, as the coordinator does while a query runs
+		time.Sleep(10 * time.Millisecond)
+		resp["nextUri"] = fmt.Sprintf("%s/v1/statement/executing/%s/%d", s.URL, id, token+1)
+		resp["stats"] = map[string]interface{}{"state": "RUNNING"}
+	case result.Error != "":
+		resp["error"] = map[string]interface{}{
+			"message":   result.Error,
+			"errorName": "GENERIC_USER_ERROR",
+			"errorType": "USER_ERROR",
+		}
+		resp["stats"] = map[string]interface{}{"state": "FAILED"}
+	default:
+		columns := make([]interface{}, len(result.Columns))
+		for i, col := range result.Columns {
+			columns[i] = map[string]interface{}{
+				"name":          col.Name,
+				"type":          col.Type,
+				"typeSignature": typeSignature(col.Type),
+			}
+		}
+		rows := result.Rows
+		if rows == nil {
+			rows = [][]interface{}{}
+		}
+		resp["columns"] = columns
+		resp["data"] = rows
+		resp["stats"] = map[string]interface{}{"state": "FINISHED"}
+		s.mu.Lock()
+		delete(s.running, id)
+		s.mu.Unlock()
+	}
+	writeJSON(w, resp)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// typeSignature builds the typeSignature object the coordinator sends for a type name
+func typeSignature(typeName string) map[string]interface{} {
+	typeName = strings.TrimSpace(typeName)
+	raw := typeName
+	var args []string
+	if open := strings.Index(typeName, "("); open >= 0 {
+		close := strings.LastIndex(typeName, ")")
+		raw = strings.TrimSpace(typeName[:open])
+		args = splitTopLevel(typeName[open+1 : close])
+		// "timestamp(3) with time zone" keeps its suffix in the raw type
+		if suffix := strings.TrimSpace(typeName[close+1:]); suffix != "" {
+			raw += " " + suffix
+		}
+	}
+
+	arguments := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		switch {
+		case raw == "row":
+			name, fieldType := arg, arg
+			if sp := strings.Index(arg, " "); sp >= 0 {
+				name, fieldType = arg[:sp], strings.TrimSpace(arg[sp+1:])
+			}
+			arguments = append(arguments, map[string]interface{}{
+				"kind": "NAMED_TYPE",
+				"value": map[string]interface{}{
+					"fieldName":     map[string]interface{}{"name": name},
+					"typeSignature": typeSignature(fieldType),
+				},
+			})
+		case isNumber(arg):
+			n, _ := strconv.ParseInt(arg, 10, 64)
+			arguments = append(arguments, map[string]interface{}{"kind": "LONG", "value": n})
+		default:
+			arguments = append(arguments, map[string]interface{}{"kind": "TYPE", "value": typeSignature(arg)})
+		}
+	}
+	return map[string]interface{}{"rawType": raw, "arguments": arguments}
+}
+
+// splitTopLevel splits type arguments on commas outside parentheses
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}